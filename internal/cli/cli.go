@@ -34,6 +34,36 @@ func NewApp(version string, start time.Time) (app *cli.App) {
 			EnvVars: []string{"MRE_INTERNAL_MONITORING_LISTENER_ADDRESS"},
 			Usage:   "internal monitoring listener address",
 		},
+		&cli.StringFlag{
+			Name:    "internal-monitoring-tls-cert",
+			EnvVars: []string{"MRE_INTERNAL_MONITORING_TLS_CERT"},
+			Usage:   "internal monitoring TLS certificate `path`, served by the exporter or presented by the monitor when mTLS is required",
+		},
+		&cli.StringFlag{
+			Name:    "internal-monitoring-tls-key",
+			EnvVars: []string{"MRE_INTERNAL_MONITORING_TLS_KEY"},
+			Usage:   "internal monitoring TLS private key `path`",
+		},
+		&cli.StringFlag{
+			Name:    "internal-monitoring-tls-ca",
+			EnvVars: []string{"MRE_INTERNAL_MONITORING_TLS_CA"},
+			Usage:   "internal monitoring TLS CA `path`, used by the monitor to verify the exporter or by the exporter to require client certificates (mTLS)",
+		},
+		&cli.StringFlag{
+			Name:    "internal-monitoring-token",
+			EnvVars: []string{"MRE_INTERNAL_MONITORING_TOKEN"},
+			Usage:   "internal monitoring bearer `token`, required by the exporter and sent by the monitor (requires TLS unless the listener is a unix socket)",
+		},
+		&cli.StringFlag{
+			Name:    "internal-monitoring-admin-token",
+			EnvVars: []string{"MRE_INTERNAL_MONITORING_ADMIN_TOKEN"},
+			Usage:   "internal monitoring bearer `token` granting the admin permission, required by the exporter for the control actions (the monitor sends it through --internal-monitoring-token)",
+		},
+		&cli.BoolFlag{
+			Name:    "internal-monitoring-reflection",
+			EnvVars: []string{"MRE_INTERNAL_MONITORING_REFLECTION"},
+			Usage:   "expose the gRPC server reflection service on the internal monitoring listener",
+		},
 	}
 
 	app.Commands = cli.CommandsByName{
@@ -46,9 +76,14 @@ func NewApp(version string, start time.Time) (app *cli.App) {
 					Name:    "config",
 					Aliases: []string{"c"},
 					EnvVars: []string{"MRE_CONFIG"},
-					Usage:   "config `file`",
+					Usage:   "config `file` (.yml, .yaml, .json or .toml)",
 					Value:   "./mend-renovate-ce-ee-exporter.yml",
 				},
+				&cli.BoolFlag{
+					Name:    "allow-unknown-keys",
+					EnvVars: []string{"MRE_ALLOW_UNKNOWN_KEYS"},
+					Usage:   "only warn about the config keys which do not match any setting, e.g. written for a more recent version, instead of failing",
+				},
 				&cli.StringFlag{
 					Name:    "redis-url",
 					EnvVars: []string{"MRE_REDIS_URL"},
@@ -66,10 +101,118 @@ func NewApp(version string, start time.Time) (app *cli.App) {
 				},
 			},
 		},
+		{
+			Name:   "doctor",
+			Usage:  "check that the exporter is able to reach its dependencies with the current config",
+			Action: cmd.ExecWrapper(cmd.Doctor),
+			Flags: cli.FlagsByName{
+				&cli.StringFlag{
+					Name:    "config",
+					Aliases: []string{"c"},
+					EnvVars: []string{"MRE_CONFIG"},
+					Usage:   "config `file` (.yml, .yaml, .json or .toml)",
+					Value:   "./mend-renovate-ce-ee-exporter.yml",
+				},
+				&cli.BoolFlag{
+					Name:    "allow-unknown-keys",
+					EnvVars: []string{"MRE_ALLOW_UNKNOWN_KEYS"},
+					Usage:   "only warn about the config keys which do not match any setting, e.g. written for a more recent version, instead of failing",
+				},
+				&cli.StringFlag{
+					Name:    "redis-url",
+					EnvVars: []string{"MRE_REDIS_URL"},
+					Usage:   "redis `url` for an HA setup (format: redis[s]://[:password@]host[:port][/db-number][?option=value]) (overrides config file parameter)",
+				},
+				&cli.StringFlag{
+					Name:    "renovate-token",
+					EnvVars: []string{"MRE_GITLAB_TOKEN"},
+					Usage:   "Renovate API access `token` (overrides config file parameter)",
+				},
+			},
+		},
+		{
+			Name:   "scrape",
+			Usage:  "pull the metrics once and write their exposition, e.g. for the node_exporter textfile collector",
+			Action: cmd.ExecWrapper(cmd.Scrape),
+			Flags: cli.FlagsByName{
+				&cli.StringFlag{
+					Name:    "config",
+					Aliases: []string{"c"},
+					EnvVars: []string{"MRE_CONFIG"},
+					Usage:   "config `file` (.yml, .yaml, .json or .toml)",
+					Value:   "./mend-renovate-ce-ee-exporter.yml",
+				},
+				&cli.BoolFlag{
+					Name:    "allow-unknown-keys",
+					EnvVars: []string{"MRE_ALLOW_UNKNOWN_KEYS"},
+					Usage:   "only warn about the config keys which do not match any setting, e.g. written for a more recent version, instead of failing",
+				},
+				&cli.StringFlag{
+					Name:    "output",
+					Aliases: []string{"o"},
+					Usage:   "`file` to atomically write the exposition to, in the Prometheus text format (defaults to stdout)",
+				},
+			},
+		},
+		{
+			Name:   "validate",
+			Usage:  "check a config file, reporting its errors along with their location",
+			Action: cmd.ExecWrapper(cmd.Validate),
+			Flags: cli.FlagsByName{
+				&cli.StringFlag{
+					Name:    "config",
+					Aliases: []string{"c"},
+					EnvVars: []string{"MRE_CONFIG"},
+					Usage:   "config `file` (.yml, .yaml, .json or .toml)",
+					Value:   "./mend-renovate-ce-ee-exporter.yml",
+				},
+				&cli.BoolFlag{
+					Name:    "allow-unknown-keys",
+					EnvVars: []string{"MRE_ALLOW_UNKNOWN_KEYS"},
+					Usage:   "only warn about the config keys which do not match any setting, e.g. written for a more recent version, instead of failing",
+				},
+			},
+		},
+		{
+			Name:  "config",
+			Usage: "config file helpers",
+			Subcommands: cli.Commands{
+				{
+					Name:   "defaults",
+					Usage:  "print the default config, each setting being commented with its type and rules",
+					Action: cmd.ExecWrapper(cmd.ConfigDefaults),
+				},
+			},
+		},
 		{
 			Name:   "monitor",
 			Usage:  "display information about the currently running exporter",
 			Action: cmd.ExecWrapper(cmd.Monitor),
+			Flags: cli.FlagsByName{
+				&cli.StringFlag{
+					Name:    "output",
+					Aliases: []string{"o"},
+					Usage:   "print snapshots in the given `format` (json, yaml or table) instead of starting the interactive UI, exits with 2 when trouble is detected",
+				},
+				&cli.BoolFlag{
+					Name:  "once",
+					Usage: "print a single snapshot and exit (default when --output is set)",
+				},
+				&cli.BoolFlag{
+					Name:  "watch",
+					Usage: "keep printing a snapshot on each telemetry update",
+				},
+				&cli.DurationFlag{
+					Name:  "timeout",
+					Usage: "maximum `duration` to wait for a single snapshot",
+					Value: 10 * time.Second,
+				},
+				&cli.Float64Flag{
+					Name:  "max-tasks-buffer-usage",
+					Usage: "tasks buffer usage `ratio` from which it is considered full, 0 disables the check",
+					Value: 1,
+				},
+			},
 		},
 	}
 
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/config"
+)
+
+// Validate checks the config, built from the file and the environment as when running the
+// exporter, and reports its errors. It exits with 1 if any is found.
+func Validate(ctx *cli.Context) (int, error) {
+	assertStringVariableDefined(ctx, "config")
+
+	filename := ctx.String("config")
+
+	format, err := config.GetTypeFromFileExtension(filename)
+	if err != nil {
+		return 1, err
+	}
+
+	content, err := os.ReadFile(filepath.Clean(filename))
+	if err != nil {
+		return 1, err
+	}
+
+	cfg, ignoredKeys, err := readConfig(ctx)
+	if err == nil {
+		err = cfg.Validate()
+	}
+
+	for _, e := range ignoredKeys {
+		fmt.Fprintf(ctx.App.ErrWriter, "%s: %s (ignored)\n", configErrorLocation(filename, e), e.Description())
+	}
+
+	if err != nil {
+		for _, e := range config.Explain(err, format, content) {
+			fmt.Fprintf(ctx.App.ErrWriter, "%s: %s\n", configErrorLocation(filename, e), e.Description())
+		}
+
+		return 1, nil
+	}
+
+	fmt.Fprintf(ctx.App.Writer, "%s: valid\n", filename)
+
+	return 0, nil
+}
+
+// configErrorLocation returns the location of the error, in the filename:line:column format.
+func configErrorLocation(filename string, e config.Error) string {
+	location := filename
+	if e.Line > 0 {
+		location += fmt.Sprintf(":%d", e.Line)
+	}
+
+	if e.Column > 0 {
+		location += fmt.Sprintf(":%d", e.Column)
+	}
+
+	return location
+}
+
+// ConfigDefaults prints the default config.
+func ConfigDefaults(ctx *cli.Context) (int, error) {
+	b, err := config.DefaultsYAML()
+	if err != nil {
+		return 1, err
+	}
+
+	if _, err = ctx.App.Writer.Write(b); err != nil {
+		return 1, err
+	}
+
+	return 0, nil
+}
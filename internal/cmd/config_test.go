@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func validateConfig(t *testing.T, content string) (exitCode int, stdout, stderr string) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	var out, errOut bytes.Buffer
+
+	app := cli.NewApp()
+	app.Writer = &out
+	app.ErrWriter = &errOut
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("config", path, "")
+
+	exitCode, err := Validate(cli.NewContext(app, fs, nil))
+	require.NoError(t, err)
+
+	return exitCode, out.String(), errOut.String()
+}
+
+func TestValidateRequiredFieldFromEnv(t *testing.T) {
+	content := "server:\n  webhook:\n    enabled: true\n"
+
+	exitCode, _, stderr := validateConfig(t, content)
+	assert.Equal(t, 1, exitCode)
+	assert.Contains(t, stderr, "secret_token")
+
+	// The exporter would run with it, the config is valid
+	t.Setenv("MRE_SERVER_WEBHOOK_SECRET_TOKEN", "secret")
+
+	exitCode, stdout, stderr := validateConfig(t, content)
+	assert.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout, "valid")
+	assert.Empty(t, stderr)
+}
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/urfave/cli/v2"
+
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/config"
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/metrics"
+)
+
+// doctorCheckTimeout bounds the duration of each of the checks.
+const doctorCheckTimeout = 10 * time.Second
+
+// List of the outcomes of a check.
+const (
+	doctorCheckPass = "PASS"
+	doctorCheckFail = "FAIL"
+	doctorCheckSkip = "SKIP"
+)
+
+// doctorResult is the outcome of a check, the hint helps fixing failures.
+type doctorResult struct {
+	outcome string
+	detail  string
+	hint    string
+}
+
+func doctorPass(format string, a ...interface{}) doctorResult {
+	return doctorResult{outcome: doctorCheckPass, detail: fmt.Sprintf(format, a...)}
+}
+
+func doctorFail(err error, hint string) doctorResult {
+	return doctorResult{outcome: doctorCheckFail, detail: err.Error(), hint: hint}
+}
+
+func doctorSkip(format string, a ...interface{}) doctorResult {
+	return doctorResult{outcome: doctorCheckSkip, detail: fmt.Sprintf(format, a...)}
+}
+
+// doctor runs the connectivity checks against a config.
+type doctor struct {
+	cfg config.Config
+
+	// renovateTokenFlag is set when the token got provided through the CLI
+	renovateTokenFlag bool
+
+	// status is the response of the status endpoint, once fetched
+	status []byte
+}
+
+// Doctor checks that the exporter is able to reach its dependencies with the
+// current config, it exits with 1 if any of the checks failed.
+func Doctor(cliCtx *cli.Context) (int, error) {
+	cfg, err := configure(cliCtx, os.Stderr)
+	if err != nil {
+		return 1, err
+	}
+
+	d := &doctor{
+		cfg:               cfg,
+		renovateTokenFlag: cliCtx.String("renovate-token") != "",
+	}
+
+	checks := []struct {
+		name string
+		run  func(ctx context.Context) doctorResult
+	}{
+		{"mend renovate url resolves", d.checkMendRenovateURL},
+		{"mend renovate token is accepted", d.checkMendRenovateToken},
+		{"mend renovate status has the expected schema", d.checkMendRenovateStatus},
+		{"redis answers", d.checkRedis},
+		{"opentelemetry endpoint accepts connections", d.checkOpenTelemetry},
+		{"listen address is free", d.checkListenAddress},
+	}
+
+	failed := false
+
+	for _, check := range checks {
+		ctx, cancel := context.WithTimeout(context.Background(), doctorCheckTimeout)
+		result := check.run(ctx)
+		cancel()
+
+		fmt.Fprintf(cliCtx.App.Writer, "[%s] %s: %s\n", result.outcome, check.name, result.detail)
+
+		if result.hint != "" {
+			fmt.Fprintf(cliCtx.App.Writer, "       hint: %s\n", result.hint)
+		}
+
+		failed = failed || result.outcome == doctorCheckFail
+	}
+
+	if failed {
+		return 1, nil
+	}
+
+	return 0, nil
+}
+
+func (d *doctor) checkMendRenovateURL(ctx context.Context) doctorResult {
+	u, err := url.Parse(d.cfg.Clients.MendRenovate.URL)
+	if err != nil {
+		return doctorFail(err, "check clients.mend_renovate.url")
+	}
+
+	if u.Hostname() == "" {
+		return doctorFail(errors.New("no host"), "clients.mend_renovate.url must be an absolute URL, e.g. https://renovate.example.com")
+	}
+
+	if net.ParseIP(u.Hostname()) != nil {
+		return doctorPass("%s is an IP address", u.Hostname())
+	}
+
+	addrs, err := net.DefaultResolver.LookupHost(ctx, u.Hostname())
+	if err != nil {
+		return doctorFail(err, "check clients.mend_renovate.url and the DNS resolution of this host")
+	}
+
+	return doctorPass("%s resolves to %s", u.Hostname(), strings.Join(addrs, ", "))
+}
+
+func (d *doctor) checkMendRenovateToken(ctx context.Context) doctorResult {
+	client := &metrics.MendRenovateClient{
+		URL:   d.cfg.Clients.MendRenovate.URL,
+		Token: d.cfg.Clients.MendRenovate.Token,
+	}
+
+	status, err := client.GetRawStatus(ctx)
+	if err != nil {
+		var apiErr *metrics.APIError
+		if !errors.As(err, &apiErr) {
+			return doctorFail(err, "check that this host can reach clients.mend_renovate.url, through a proxy if required")
+		}
+
+		switch apiErr.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			hint := "check clients.mend_renovate.token, it must match the API secret of the Mend Renovate server"
+			if d.renovateTokenFlag {
+				hint += ", note the token set through --renovate-token (MRE_GITLAB_TOKEN) overrides the config file"
+			}
+
+			return doctorFail(fmt.Errorf("%d: token rejected", apiErr.StatusCode), hint)
+		case http.StatusNotFound:
+			return doctorFail(err, "check that clients.mend_renovate.url points to the Mend Renovate server, without any path")
+		default:
+			return doctorFail(err, "check the logs of the Mend Renovate server")
+		}
+	}
+
+	d.status = status
+
+	return doctorPass("%s responded", d.cfg.Clients.MendRenovate.URL)
+}
+
+func (d *doctor) checkMendRenovateStatus(_ context.Context) doctorResult {
+	if d.status == nil {
+		return doctorSkip("the status could not be fetched")
+	}
+
+	if err := metrics.CheckStatusSchema(d.status); err != nil {
+		return doctorFail(err, "the Mend Renovate server version may not be supported, or clients.mend_renovate.url may point to another service")
+	}
+
+	return doctorPass("%d bytes, all the expected fields are set", len(d.status))
+}
+
+func (d *doctor) checkRedis(ctx context.Context) doctorResult {
+	if d.cfg.Redis.URL == "" {
+		return doctorSkip("redis.url is not set, the local store is used")
+	}
+
+	opt, err := redis.ParseURL(d.cfg.Redis.URL)
+	if err != nil {
+		return doctorFail(err, "redis.url format: redis[s]://[:password@]host[:port][/db-number][?option=value]")
+	}
+
+	client := redis.NewClient(opt)
+	defer client.Close()
+
+	if err = client.Ping(ctx).Err(); err != nil {
+		return doctorFail(err, "check that redis is running and reachable from this host, and the credentials of redis.url")
+	}
+
+	return doctorPass("%s answered", opt.Addr)
+}
+
+func (d *doctor) checkOpenTelemetry(ctx context.Context) doctorResult {
+	endpoint := d.cfg.OpenTelemetry.GRPCEndpoint
+	if endpoint == "" {
+		return doctorSkip("opentelemetry.grpc_endpoint is not set, tracing is disabled")
+	}
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", endpoint)
+	if err != nil {
+		return doctorFail(err, "check opentelemetry.grpc_endpoint (host:port), the exporter waits for it to be reachable before starting")
+	}
+	defer conn.Close()
+
+	return doctorPass("%s accepted the connection", endpoint)
+}
+
+func (d *doctor) checkListenAddress(_ context.Context) doctorResult {
+	l, err := net.Listen("tcp", d.cfg.Server.ListenAddress)
+	if err != nil {
+		return doctorFail(err, "another process, possibly a running exporter, is listening on server.listen_address")
+	}
+
+	_ = l.Close()
+
+	return doctorPass("%s is available", d.cfg.Server.ListenAddress)
+}
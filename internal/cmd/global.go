@@ -4,6 +4,8 @@ import (
 	"net/url"
 
 	"github.com/urfave/cli/v2"
+
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/monitor"
 )
 
 // Global is used for globally shared exporter config.
@@ -11,6 +13,9 @@ type Global struct {
 	// InternalMonitoringListenerAddress can be used to access
 	// some metrics related to the exporter internals
 	InternalMonitoringListenerAddress *url.URL
+
+	// InternalMonitoringOptions secure the access to the internal monitoring
+	InternalMonitoringOptions monitor.Options
 }
 
 func parseGlobalFlags(ctx *cli.Context) (cfg Global, err error) {
@@ -18,5 +23,14 @@ func parseGlobalFlags(ctx *cli.Context) (cfg Global, err error) {
 		cfg.InternalMonitoringListenerAddress, err = url.Parse(listenerAddr)
 	}
 
+	cfg.InternalMonitoringOptions = monitor.Options{
+		TLSCertFile: ctx.String("internal-monitoring-tls-cert"),
+		TLSKeyFile:  ctx.String("internal-monitoring-tls-key"),
+		TLSCAFile:   ctx.String("internal-monitoring-tls-ca"),
+		Token:       ctx.String("internal-monitoring-token"),
+		AdminToken:  ctx.String("internal-monitoring-admin-token"),
+		Reflection:  ctx.Bool("internal-monitoring-reflection"),
+	}
+
 	return
 }
@@ -1,8 +1,14 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	"github.com/urfave/cli/v2"
 
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/monitor"
+	pb "github.com/xnok/mend-renovate-ce-ee-exporter/pkg/monitor/protobuf"
 	monitorUI "github.com/xnok/mend-renovate-ce-ee-exporter/pkg/monitor/ui"
 )
 
@@ -13,10 +19,91 @@ func Monitor(ctx *cli.Context) (int, error) {
 		return 1, err
 	}
 
+	if output := ctx.String("output"); output != "" {
+		return monitorOutput(ctx, cfg, output)
+	}
+
 	monitorUI.Start(
 		ctx.App.Version,
 		cfg.InternalMonitoringListenerAddress,
+		cfg.InternalMonitoringOptions,
 	)
 
 	return 0, nil
 }
+
+// monitorOutput prints snapshots of the exporter for scripts, it exits with 2 if the
+// last snapshot indicates trouble.
+func monitorOutput(ctx *cli.Context, cfg Global, output string) (int, error) {
+	switch output {
+	case monitor.OutputJSON, monitor.OutputYAML, monitor.OutputTable:
+	default:
+		return 1, fmt.Errorf("unsupported output format '%s', expected one of %s, %s or %s", output, monitor.OutputJSON, monitor.OutputYAML, monitor.OutputTable)
+	}
+
+	watch := ctx.Bool("watch")
+	if watch && ctx.Bool("once") {
+		return 1, fmt.Errorf("'--once' and '--watch' cannot be used together")
+	}
+
+	if cfg.InternalMonitoringListenerAddress == nil {
+		return 1, fmt.Errorf("'--internal-monitoring-listener-address' must be set")
+	}
+
+	var (
+		runCtx context.Context
+		cancel context.CancelFunc
+	)
+
+	if watch {
+		runCtx, cancel = context.WithCancel(ctx.Context)
+	} else {
+		runCtx, cancel = context.WithTimeout(ctx.Context, ctx.Duration("timeout"))
+	}
+
+	defer cancel()
+
+	client := monitor.NewClient(runCtx, cfg.InternalMonitoringListenerAddress, cfg.InternalMonitoringOptions)
+	thresholds := monitor.SnapshotThresholds{
+		MaxTasksBufferUsage: ctx.Float64("max-tasks-buffer-usage"),
+	}
+
+	stream, err := client.GetTelemetry(runCtx, &pb.Empty{})
+	if err != nil {
+		return 1, err
+	}
+
+	for {
+		telemetry, err := stream.Recv()
+		if err != nil {
+			return 1, err
+		}
+
+		config, err := client.GetConfig(runCtx, &pb.Empty{})
+		if err != nil {
+			return 1, err
+		}
+
+		snapshot, err := monitor.NewSnapshot(telemetry, config, thresholds, time.Now())
+		if err != nil {
+			return 1, err
+		}
+
+		if watch && output != monitor.OutputJSON {
+			// Separate the snapshots of the stream
+			fmt.Fprintln(ctx.App.Writer, "---")
+		}
+
+		if err = snapshot.Write(ctx.App.Writer, output); err != nil {
+			return 1, err
+		}
+
+		if !watch {
+			if !snapshot.Healthy() {
+				return 2, nil
+			}
+
+			return 0, nil
+		}
+	}
+}
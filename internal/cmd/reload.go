@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/controller"
+)
+
+// configWatchInterval is how often the config file is checked for changes.
+const configWatchInterval = 10 * time.Second
+
+// watchConfig reloads the config of the controller upon SIGHUP or whenever the config file
+// changes, the file being checked at the given interval.
+func watchConfig(ctx context.Context, cliCtx *cli.Context, c *controller.Controller, interval time.Duration) {
+	onReload := make(chan os.Signal, 1)
+	signal.Notify(onReload, syscall.SIGHUP)
+	defer signal.Stop(onReload)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	path := cliCtx.String("config")
+	checksum, _ := fileChecksum(path)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-onReload:
+			log.Info("received SIGHUP, reloading config..")
+		case <-ticker.C:
+			sum, err := fileChecksum(path)
+			if err != nil {
+				log.WithError(err).Debug("reading config file checksum")
+
+				continue
+			}
+
+			if sum == checksum {
+				continue
+			}
+
+			log.WithField("path", path).Info("config file changed, reloading config..")
+		}
+
+		checksum, _ = fileChecksum(path)
+		reloadConfig(ctx, cliCtx, c)
+	}
+}
+
+// reloadConfig loads the config file and applies it to the controller,
+// the current config is kept if anything goes wrong.
+func reloadConfig(ctx context.Context, cliCtx *cli.Context, c *controller.Controller) {
+	cfg, err := loadConfig(cliCtx)
+	if err != nil {
+		c.ConfigReloadTelemetry.Record(false)
+		log.WithContext(ctx).
+			WithError(err).
+			Error("loading config, keeping the current one")
+
+		return
+	}
+
+	if err = c.Reload(ctx, cfg); err != nil {
+		log.WithContext(ctx).
+			WithError(err).
+			Error("invalid config, keeping the current one")
+
+		return
+	}
+
+	if err = configureLogger(cfg); err != nil {
+		log.WithContext(ctx).
+			WithError(err).
+			Warn("configuring logger")
+	}
+}
+
+func fileChecksum(path string) ([sha256.Size]byte, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+
+	return sha256.Sum256(b), nil
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/config"
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/controller"
+)
+
+func newWatchConfigTest(t *testing.T, content string) (cliCtx *cli.Context, path string, c *controller.Controller) {
+	path = filepath.Join(t.TempDir(), "config.yml")
+	writeConfig(t, path, content)
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("config", path, "")
+	cliCtx = cli.NewContext(cli.NewApp(), fs, nil)
+
+	cfg, err := loadConfig(cliCtx)
+	require.NoError(t, err)
+
+	ctrl, err := controller.New(context.Background(), cfg, "test")
+	require.NoError(t, err)
+
+	return cliCtx, path, &ctrl
+}
+
+// writeConfig replaces the config file at once, the watcher could otherwise read it half written.
+func writeConfig(t *testing.T, path, content string) {
+	tmp := path + ".tmp"
+	require.NoError(t, os.WriteFile(tmp, []byte(content), 0o600))
+	require.NoError(t, os.Rename(tmp, path))
+}
+
+func lastReloadSuccess(c *controller.Controller) float64 {
+	return testutil.ToFloat64(c.ConfigReloadTelemetry.LastReloadSuccess.(*prometheus.GaugeVec).With(prometheus.Labels{}))
+}
+
+func TestWatchConfigFileChange(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cliCtx, path, c := newWatchConfigTest(t, "pull:\n  metrics:\n    interval_seconds: 30\n")
+
+	go watchConfig(ctx, cliCtx, c, 10*time.Millisecond)
+
+	// The file keeps changing until the watcher, which may not have started yet, notices it
+	writes := 0
+	assert.Eventually(t, func() bool {
+		writes++
+		writeConfig(t, path, fmt.Sprintf("pull:\n  metrics:\n    interval_seconds: 60\n# %d\n", writes))
+
+		return c.Config().Pull.Metrics.IntervalSeconds == 60
+	}, 5*time.Second, 50*time.Millisecond)
+	assert.Equal(t, float64(1), lastReloadSuccess(c))
+
+	// Invalid configs are not applied
+	writeConfig(t, path, "pull:\n  metrics:\n    interval_seconds: 0\n")
+	assert.Eventually(t, func() bool {
+		return lastReloadSuccess(c) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 60, c.Config().Pull.Metrics.IntervalSeconds)
+
+	// As well as the ones which cannot be parsed
+	writeConfig(t, path, "pull:\n  metrics:\n    interval_seconds: 90\n")
+	assert.Eventually(t, func() bool {
+		return lastReloadSuccess(c) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	writeConfig(t, path, "pull: [\n")
+	assert.Eventually(t, func() bool {
+		return lastReloadSuccess(c) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 90, c.Config().Pull.Metrics.IntervalSeconds)
+}
+
+func TestWatchConfigSIGHUP(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cliCtx, path, c := newWatchConfigTest(t, "pull:\n  metrics:\n    interval_seconds: 30\n")
+
+	// Prevents the signal from terminating the test if sent before the watcher listens to it
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	// The file is not checked in time, only the signal triggers the reload
+	go watchConfig(ctx, cliCtx, c, time.Hour)
+
+	writeConfig(t, path, "pull:\n  metrics:\n    interval_seconds: 60\n")
+	assert.Eventually(t, func() bool {
+		require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+
+		return c.Config().Pull.Metrics.IntervalSeconds == 60
+	}, 5*time.Second, 50*time.Millisecond)
+
+	assert.Equal(t, config.SchedulerConfig{OnInit: true, Scheduled: true, IntervalSeconds: 60}, config.SchedulerConfig(c.Config().Pull.Metrics))
+}
@@ -11,15 +11,16 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v2"
-	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/metrics"
 
 	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/controller"
+	// Register the metrics sources
+	_ "github.com/xnok/mend-renovate-ce-ee-exporter/pkg/metrics"
 	monitoringServer "github.com/xnok/mend-renovate-ce-ee-exporter/pkg/monitor"
 )
 
 // Run launches the exporter.
 func Run(cliCtx *cli.Context) (int, error) {
-	cfg, err := configure(cliCtx)
+	cfg, err := configure(cliCtx, os.Stdout)
 	if err != nil {
 		return 1, err
 	}
@@ -32,8 +33,12 @@ func Run(cliCtx *cli.Context) (int, error) {
 		return 1, err
 	}
 
-	// delegate the task registration to metrics Controllers
-	metrics.NewMendRenovateController(&c).Configure(ctx)
+	// delegate the task registration to the metrics sources
+	c.ConfigureSources(ctx)
+
+	if cfg.Server.Webhook.Enabled && cfg.Server.Webhook.Relay.Enabled {
+		c.ConfigureWebhookRelay(ctx)
+	}
 
 	global, err := parseGlobalFlags(cliCtx)
 	if err != nil {
@@ -42,14 +47,13 @@ func Run(cliCtx *cli.Context) (int, error) {
 
 	// Start the monitoring RPC server
 	go func(c *controller.Controller) {
-		s := monitoringServer.NewServer(
-			c.Config,
-			c.Store,
-			c.TaskController.TaskSchedulingMonitoring,
-		)
+		s := monitoringServer.NewServer(c, global.InternalMonitoringOptions)
 		s.Serve(global.InternalMonitoringListenerAddress)
 	}(&c)
 
+	// Config reloads
+	go watchConfig(ctx, cliCtx, &c, configWatchInterval)
+
 	// Graceful shutdowns
 	onShutdown := make(chan os.Signal, 1)
 	signal.Notify(onShutdown, syscall.SIGINT, syscall.SIGTERM, syscall.SIGABRT)
@@ -85,6 +89,11 @@ func Run(cliCtx *cli.Context) (int, error) {
 		mux.HandleFunc("/webhook", c.WebhookHandler)
 	}
 
+	// admin endpoints
+	if cfg.Server.Admin.Enabled {
+		mux.HandleFunc("/admin/tasks/", c.AdminTaskHandler)
+	}
+
 	go func() {
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			log.WithContext(ctx).
@@ -99,6 +108,7 @@ func Run(cliCtx *cli.Context) (int, error) {
 			"pprof-endpoint-enabled":       cfg.Server.EnablePprof,
 			"metrics-endpoint-enabled":     cfg.Server.Metrics.Enabled,
 			"webhook-endpoint-enabled":     cfg.Server.Webhook.Enabled,
+			"admin-endpoint-enabled":       cfg.Server.Admin.Enabled,
 			"openmetrics-encoding-enabled": cfg.Server.Metrics.EnableOpenmetricsEncoding,
 			"controller-uuid":              c.UUID,
 		},
@@ -115,6 +125,16 @@ func Run(cliCtx *cli.Context) (int, error) {
 		return 1, err
 	}
 
+	controllerContext, forceControllerShutdown := context.WithTimeout(
+		context.Background(),
+		time.Duration(c.Config().Scheduler.ShutdownTimeoutSeconds)*time.Second,
+	)
+	defer forceControllerShutdown()
+
+	if err := c.Shutdown(controllerContext); err != nil {
+		return 1, err
+	}
+
 	log.Info("stopped!")
 
 	return 0, nil
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/controller"
+	// Register the metrics sources
+	_ "github.com/xnok/mend-renovate-ce-ee-exporter/pkg/metrics"
+)
+
+// Scrape pulls the metrics once and writes their exposition to stdout or to a file,
+// it exits with 1 if any of the pulls failed.
+func Scrape(cliCtx *cli.Context) (int, error) {
+	cfg, err := configure(cliCtx, os.Stderr)
+	if err != nil {
+		return 1, err
+	}
+
+	// The scrape is not meant to share its data with running exporters
+	cfg.Redis.URL = ""
+
+	ctx, ctxCancel := context.WithCancel(context.Background())
+	defer ctxCancel()
+
+	c, err := controller.New(ctx, cfg, cliCtx.App.Version)
+	if err != nil {
+		return 1, err
+	}
+
+	c.RegisterSources(ctx)
+
+	scrapeErr := c.RunTasks(ctx)
+	if scrapeErr != nil {
+		log.WithContext(ctx).
+			WithError(scrapeErr).
+			Error("scraping metrics")
+	}
+
+	if output := cliCtx.String("output"); output != "" {
+		// The textfile collector only reads the Prometheus text format
+		err = writeFileAtomically(output, func(w io.Writer) error {
+			return c.WriteMetrics(ctx, w, false)
+		})
+	} else {
+		err = c.WriteMetrics(ctx, cliCtx.App.Writer, cfg.Server.Metrics.EnableOpenmetricsEncoding)
+	}
+
+	if err != nil {
+		return 1, err
+	}
+
+	shutdownCtx, forceShutdown := context.WithTimeout(
+		context.Background(),
+		time.Duration(cfg.Scheduler.ShutdownTimeoutSeconds)*time.Second,
+	)
+	defer forceShutdown()
+
+	if err = c.Shutdown(shutdownCtx); err != nil {
+		return 1, err
+	}
+
+	if scrapeErr != nil {
+		return 1, nil
+	}
+
+	return 0, nil
+}
+
+// writeFileAtomically writes the file through a temporary one which is renamed once complete,
+// readers never get to see a partially written file.
+func writeFileAtomically(filename string, write func(w io.Writer) error) (err error) {
+	f, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".*")
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			_ = f.Close()
+			_ = os.Remove(f.Name())
+		}
+	}()
+
+	if err = write(f); err != nil {
+		return
+	}
+
+	if err = f.Chmod(0o644); err != nil {
+		return
+	}
+
+	if err = f.Close(); err != nil {
+		return
+	}
+
+	return os.Rename(f.Name(), filename)
+}
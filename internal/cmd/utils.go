@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"errors"
+	"io"
 	stdlibLog "log"
 	"os"
 	"time"
@@ -17,32 +19,27 @@ import (
 
 var start time.Time
 
-func configure(ctx *cli.Context) (cfg config.Config, err error) {
+// configure loads the config and sets up the logger, the logs are written to
+// logOutput as the commands writing their results to stdout need it for themselves.
+func configure(ctx *cli.Context, logOutput io.Writer) (cfg config.Config, err error) {
 	start = ctx.App.Metadata["startTime"].(time.Time)
 
 	assertStringVariableDefined(ctx, "config")
 
-	cfg, err = config.ParseFile(ctx.String("config"))
-	if err != nil {
+	if cfg, err = loadConfig(ctx); err != nil {
 		return
 	}
 
-	configCliOverrides(ctx, &cfg)
-
 	if err = cfg.Validate(); err != nil {
 		return
 	}
 
-	// Configure logger
-	if err = logger.Configure(
-		logger.Config{
-			Level:  cfg.Log.Level,
-			Format: cfg.Log.Format,
-		},
-	); err != nil {
+	if err = configureLogger(cfg); err != nil {
 		return
 	}
 
+	log.SetOutput(logOutput)
+
 	log.AddHook(
 		otellogrus.NewHook(
 			otellogrus.WithLevels(
@@ -63,12 +60,79 @@ func configure(ctx *cli.Context) (cfg config.Config, err error) {
 	return
 }
 
-func exit(exitCode int, err error) cli.ExitCoder {
-	defer log.WithFields(
-		log.Fields{
-			"execution-time": time.Since(start),
+// loadConfig reads the config then applies the CLI overrides, the returned config still has to be validated.
+func loadConfig(ctx *cli.Context) (cfg config.Config, err error) {
+	var ignoredKeys config.UnknownKeysError
+
+	if cfg, ignoredKeys, err = readConfig(ctx); err != nil {
+		return
+	}
+
+	for _, e := range ignoredKeys {
+		log.WithFields(
+			log.Fields{
+				"path":   e.Path,
+				"line":   e.Line,
+				"column": e.Column,
+			},
+		).Warn("ignoring unknown config key")
+	}
+
+	configCliOverrides(ctx, &cfg)
+
+	return
+}
+
+// readConfig parses the config file, applies the environment variables and reads the secret files.
+// It is shared by the commands running the exporter and the one validating its config, in order for
+// them to agree on what a valid config is.
+func readConfig(ctx *cli.Context) (cfg config.Config, ignoredKeys config.UnknownKeysError, err error) {
+	if cfg, ignoredKeys, err = parseConfigFile(ctx); err != nil {
+		return
+	}
+
+	if err = cfg.ApplyEnv(os.Environ()); err != nil {
+		return
+	}
+
+	err = cfg.ReadSecretFiles()
+
+	return
+}
+
+// parseConfigFile parses the config file, the keys which do not match any setting
+// are returned instead of failing when allowed.
+func parseConfigFile(ctx *cli.Context) (cfg config.Config, ignoredKeys config.UnknownKeysError, err error) {
+	filename := ctx.String("config")
+
+	cfg, err = config.ParseFile(filename)
+	if !errors.As(err, &ignoredKeys) || !ctx.Bool("allow-unknown-keys") {
+		return cfg, nil, err
+	}
+
+	cfg, err = config.ParseFile(filename, config.AllowUnknownKeys())
+
+	return
+}
+
+// configureLogger applies the log settings of the config.
+func configureLogger(cfg config.Config) error {
+	return logger.Configure(
+		logger.Config{
+			Level:  cfg.Log.Level,
+			Format: cfg.Log.Format,
 		},
-	).Debug("exited..")
+	)
+}
+
+func exit(exitCode int, err error) cli.ExitCoder {
+	defer func() {
+		log.WithFields(
+			log.Fields{
+				"execution-time": time.Since(start),
+			},
+		).Debug("exited..")
+	}()
 
 	if err != nil {
 		log.WithError(err).Error()
@@ -95,6 +159,10 @@ func configCliOverrides(ctx *cli.Context, cfg *config.Config) {
 	if ctx.String("redis-url") != "" {
 		cfg.Redis.URL = ctx.String("redis-url")
 	}
+
+	if ctx.String("renovate-token") != "" {
+		cfg.Clients.MendRenovate.Token = ctx.String("renovate-token")
+	}
 }
 
 func assertStringVariableDefined(ctx *cli.Context, k string) {
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 
 	"github.com/creasty/defaults"
 	"github.com/go-playground/validator/v10"
@@ -11,6 +12,8 @@ import (
 
 var validate *validator.Validate
 
+// Config holds the settings of the exporter. They are taken from the CLI flags first,
+// then the environment variables (see ApplyEnv), then the config file, then the defaults.
 type Config struct {
 	// Log configuration for the exporter
 	Log Log `yaml:"log"`
@@ -35,17 +38,33 @@ type Config struct {
 
 	// Clients configuration for the API client use to scrape
 	Clients Clients `yaml:"clients"`
+
+	// Renovate configuration, used to identify its activity on the platforms
+	Renovate Renovate `yaml:"renovate"`
+
+	// Sources configuration, indexed by source name
+	Sources map[string]Source `yaml:"sources"`
 }
 
 // Validate will throw an error if the Config parameters are whether incomplete or incorrect.
 func (c Config) Validate() error {
 	if validate == nil {
 		validate = validator.New()
+		// Report the fields using the names they have in the config file
+		validate.RegisterTagNameFunc(yamlName)
 	}
 
 	return validate.Struct(c)
 }
 
+// SourceEnabled returns whether the source with the given name should be configured.
+// Sources are enabled unless explicitly disabled.
+func (c Config) SourceEnabled(name string) bool {
+	s, ok := c.Sources[name]
+
+	return !ok || s.Enabled
+}
+
 // Server ..
 type Server struct {
 	// Enable profiling pages
@@ -56,6 +75,8 @@ type Server struct {
 
 	Metrics ServerMetrics `yaml:"metrics"`
 	Webhook ServerWebhook `yaml:"webhook"`
+	Health  ServerHealth  `yaml:"health"`
+	Admin   ServerAdmin   `yaml:"admin"`
 }
 
 // ServerMetrics ..
@@ -72,8 +93,54 @@ type ServerWebhook struct {
 	// Enable /webhook endpoint to support webhook requests
 	Enabled bool `default:"false" yaml:"enabled"`
 
-	// Secret token to authenticate legitimate webhook requests coming from the GitLab server
+	// Secret token to authenticate legitimate webhook requests coming from the GitLab server,
+	// or to verify the signature of the ones coming from the GitHub server
 	SecretToken string `validate:"required_if=Enabled true" yaml:"secret_token"`
+
+	// File to read the secret token from when it is not set, eg: a mounted Kubernetes secret
+	SecretTokenFile string `yaml:"secret_token_file"`
+
+	// Delay during which the webhook requests are coalesced into a single scrape
+	DebounceSeconds int `default:"5" validate:"gte=0" yaml:"debounce_seconds"`
+
+	Relay ServerWebhookRelay `yaml:"relay"`
+}
+
+// ServerWebhookRelay ..
+type ServerWebhookRelay struct {
+	// Forward the webhook requests to Mend Renovate once authenticated
+	Enabled bool `default:"false" yaml:"enabled"`
+
+	// URL to forward the webhook requests to, defaults to the /webhook endpoint of the Mend Renovate client URL
+	URL string `validate:"omitempty,url" yaml:"url"`
+
+	// Timeout of the forwarded requests
+	TimeoutSeconds int `default:"10" validate:"required_if=Enabled true,gte=0" yaml:"timeout_seconds"`
+
+	// Maximum number of deliveries kept in the store whilst Mend Renovate is unreachable, 0 disables the buffering
+	MaxBufferedDeliveries int `default:"1000" validate:"gte=0" yaml:"max_buffered_deliveries"`
+
+	// Interval at which the buffered deliveries are relayed again
+	RetryIntervalSeconds int `default:"30" validate:"required_if=Enabled true,gte=0" yaml:"retry_interval_seconds"`
+}
+
+// ServerHealth ..
+type ServerHealth struct {
+	// Number of pull intervals without any successful scrape after which
+	// the exporter is not considered ready anymore, 0 disables the check
+	MaxPullIntervalsWithoutSuccess int `default:"3" validate:"gte=0" yaml:"max_pull_intervals_without_success"`
+}
+
+// ServerAdmin ..
+type ServerAdmin struct {
+	// Enable /admin endpoints to trigger tasks on demand
+	Enabled bool `default:"false" yaml:"enabled"`
+
+	// Bearer token to authenticate the admin requests
+	Token string `validate:"required_if=Enabled true" yaml:"token"`
+
+	// File to read the token from when it is not set
+	TokenFile string `yaml:"token_file"`
 }
 
 // Log holds runtime logging configuration.
@@ -97,12 +164,19 @@ type Redis struct {
 	// URL used to connect onto the redis endpoint
 	// format: redis[s]://[:password@]host[:port][/db-number][?option=value])
 	URL string `yaml:"url"`
+
+	// File to read the URL from when it is not set, as it may embed credentials
+	URLFile string `yaml:"url_file"`
 }
 
 // Scheduler ..
 type Scheduler struct {
 	// BufferSize for the task/job queue
 	MaximumJobsQueueSize int `yaml:"maximum_jobs_queue_size"`
+
+	// Time given to the queued tasks to complete when the exporter stops,
+	// 0 releases them straight away
+	ShutdownTimeoutSeconds int `default:"30" validate:"gte=0" yaml:"shutdown_timeout_seconds"`
 }
 
 // SchedulerConfig ..
@@ -156,9 +230,16 @@ func New() (c Config) {
 	return
 }
 
-// ToYAML ..
+// ToYAML returns the config as YAML, with the secrets redacted.
 func (c Config) ToYAML() string {
 	c.Server.Webhook.SecretToken = "*******"
+	c.Server.Admin.Token = "*******"
+	c.Clients.MendRenovate.Token = "*******"
+
+	// URLs may embed credentials as well
+	c.Server.Webhook.Relay.URL = redactURL(c.Server.Webhook.Relay.URL)
+	c.Clients.MendRenovate.URL = redactURL(c.Clients.MendRenovate.URL)
+	c.Redis.URL = redactURL(c.Redis.URL)
 
 	b, err := yaml.Marshal(c)
 	if err != nil {
@@ -168,6 +249,20 @@ func (c Config) ToYAML() string {
 	return string(b)
 }
 
+// redactURL masks the password of the URL, the whole URL is masked if it cannot be parsed.
+func redactURL(rawURL string) string {
+	if rawURL == "" {
+		return ""
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "*******"
+	}
+
+	return u.Redacted()
+}
+
 type Clients struct {
 	// MendRenovate configuration to talk to the server
 	MendRenovate MendRenovate `yaml:"mend_renovate"`
@@ -176,4 +271,33 @@ type Clients struct {
 type MendRenovate struct {
 	URL   string `yaml:"url"`
 	Token string `yaml:"token"`
+
+	// File to read the token from when it is not set
+	TokenFile string `yaml:"token_file"`
+}
+
+// Renovate ..
+type Renovate struct {
+	// Username of the Renovate bot authoring the pull requests
+	BotUsername string `default:"renovate[bot]" yaml:"bot_username"`
+
+	// Prefix of the branches created by Renovate
+	BranchPrefix string `default:"renovate/" yaml:"branch_prefix"`
+}
+
+// Source ..
+type Source struct {
+	// Enable the source
+	Enabled bool `default:"true" yaml:"enabled"`
+}
+
+// UnmarshalYAML ensures the default values are set, as they do not get applied on map values.
+// The value is decoded through the unmarshal function, rather than yaml.Node.Decode, for the
+// decoder to keep reporting the unknown keys.
+func (s *Source) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	defaults.MustSet(s)
+
+	type plain Source
+
+	return unmarshal((*plain)(s))
 }
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultsYAML returns the default config as YAML, each setting being commented
+// with its type and the rules it must satisfy.
+func DefaultsYAML() ([]byte, error) {
+	root := defaultsNode(reflect.ValueOf(New()), []string{EnvPrefix})
+	root.HeadComment = "Default configuration of mend-renovate-ce-ee-exporter\n" +
+		"Settings are taken from the CLI flags first, then the environment variables, then this file, then the defaults"
+
+	var b bytes.Buffer
+
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}}); err != nil {
+		return nil, err
+	}
+
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return append([]byte("---\n"), b.Bytes()...), nil
+}
+
+// defaultsNode returns the mapping of the fields of the struct along with their comments,
+// the path is used to name the environment variables overriding them.
+func defaultsNode(v reflect.Value, path []string) *yaml.Node {
+	n := &yaml.Node{Kind: yaml.MappingNode}
+
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		key := &yaml.Node{Kind: yaml.ScalarNode, Value: yamlName(f)}
+		fieldPath := append(path[:len(path):len(path)], yamlName(f))
+
+		var value *yaml.Node
+
+		switch f.Type.Kind() {
+		case reflect.Struct:
+			value = defaultsNode(v.Field(i), fieldPath)
+		case reflect.Map:
+			value = &yaml.Node{Kind: yaml.MappingNode, Style: yaml.FlowStyle}
+			key.HeadComment = fmt.Sprintf(
+				"indexed by name, each entry holding (env %s_<NAME>_<SETTING>):\n%s",
+				envName(fieldPath), describeMapValue(f.Type.Elem()),
+			)
+		default:
+			value = &yaml.Node{}
+			if err := value.Encode(v.Field(i).Interface()); err != nil {
+				panic(err)
+			}
+
+			key.HeadComment = fmt.Sprintf("%s (env %s)", describeField(f, v.Type()), envName(fieldPath))
+		}
+
+		n.Content = append(n.Content, key, value)
+	}
+
+	return n
+}
+
+// describeField returns the type of the field followed by its validation rules.
+func describeField(f reflect.StructField, parent reflect.Type) string {
+	description := []string{typeName(f.Type)}
+
+	for _, rule := range strings.Split(f.Tag.Get("validate"), ",") {
+		tag, param, _ := strings.Cut(rule, "=")
+		if tag == "" || tag == "omitempty" {
+			continue
+		}
+
+		description = append(description, describeRule(tag, param, parent))
+	}
+
+	return strings.Join(description, ", ")
+}
+
+// describeMapValue documents the fields of the values of a map, along with their default.
+func describeMapValue(t reflect.Type) string {
+	lines := []string{}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		line := fmt.Sprintf("  %s: %s", yamlName(f), describeField(f, t))
+		if d, ok := f.Tag.Lookup("default"); ok {
+			line += ", defaults to " + d
+		}
+
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func typeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	default:
+		return t.Kind().String()
+	}
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultsYAML(t *testing.T) {
+	b, err := DefaultsYAML()
+	require.NoError(t, err)
+
+	// The output must be a valid config matching the defaults
+	cfg, err := Parse(FormatYAML, b)
+	require.NoError(t, err)
+
+	expected := New()
+	expected.Sources = map[string]Source{}
+
+	assert.Equal(t, expected, cfg)
+	assert.NoError(t, cfg.Validate())
+
+	assert.Contains(t, string(b), "  # integer, must be greater than or equal to 1 (env MRE_PULL_METRICS_INTERVAL_SECONDS)\n    interval_seconds: 30\n")
+	assert.Contains(t, string(b), "    # string, is required when enabled is true (env MRE_SERVER_WEBHOOK_SECRET_TOKEN)\n    secret_token: \"\"\n")
+	assert.Contains(t, string(b), "# indexed by name, each entry holding (env MRE_SOURCES_<NAME>_<SETTING>):\n#   enabled: boolean, defaults to true\nsources: {}\n")
+}
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/creasty/defaults"
+)
+
+// EnvPrefix prefixes the names of the environment variables overriding the config.
+const EnvPrefix = "MRE"
+
+// envName returns the name of the environment variable overriding the setting at the given path.
+func envName(path []string) string {
+	return strings.ToUpper(strings.Join(path, "_"))
+}
+
+// ApplyEnv overrides the settings with the environment variables named after their
+// path in the config file, e.g. MRE_PULL_METRICS_INTERVAL_SECONDS for pull.metrics.interval_seconds.
+// The entries of the maps are addressed by their key, e.g. MRE_SOURCES_MEND_RENOVATE_ENABLED.
+func (c *Config) ApplyEnv(environ []string) error {
+	env := make(map[string]string)
+
+	for _, kv := range environ {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			env[k] = v
+		}
+	}
+
+	return applyEnv(reflect.ValueOf(c).Elem(), []string{EnvPrefix}, env)
+}
+
+func applyEnv(v reflect.Value, path []string, env map[string]string) error {
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		fieldPath := append(path[:len(path):len(path)], yamlName(f))
+
+		var err error
+
+		switch f.Type.Kind() {
+		case reflect.Struct:
+			err = applyEnv(v.Field(i), fieldPath, env)
+		case reflect.Map:
+			err = applyMapEnv(v.Field(i), fieldPath, env)
+		default:
+			name := envName(fieldPath)
+			if value, ok := env[name]; ok {
+				if err = setEnvValue(v.Field(i), value); err != nil {
+					err = fmt.Errorf("%s: %w", name, err)
+				}
+			}
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// applyMapEnv overrides the entries of a map of structs, the entries which are
+// not part of the map yet are added with their default values.
+func applyMapEnv(m reflect.Value, path []string, env map[string]string) error {
+	prefix := envName(path) + "_"
+	leaves := envLeaves(m.Type().Elem(), nil)
+
+	keys := make(map[string]bool)
+
+	for name := range env {
+		rest, ok := strings.CutPrefix(name, prefix)
+		if !ok {
+			continue
+		}
+
+		for _, leaf := range leaves {
+			if key, ok := strings.CutSuffix(rest, "_"+leaf); ok && key != "" {
+				keys[strings.ToLower(key)] = true
+			}
+		}
+	}
+
+	if len(keys) > 0 && m.IsNil() {
+		m.Set(reflect.MakeMap(m.Type()))
+	}
+
+	for key := range keys {
+		mapKey := reflect.ValueOf(key)
+
+		// The key may not have been written in lowercase in the config file
+		for _, k := range m.MapKeys() {
+			if strings.EqualFold(k.String(), key) {
+				mapKey = k
+			}
+		}
+
+		entry := reflect.New(m.Type().Elem())
+		if existing := m.MapIndex(mapKey); existing.IsValid() {
+			entry.Elem().Set(existing)
+		} else if err := defaults.Set(entry.Interface()); err != nil {
+			return err
+		}
+
+		if err := applyEnv(entry.Elem(), append(path[:len(path):len(path)], key), env); err != nil {
+			return err
+		}
+
+		m.SetMapIndex(mapKey, entry.Elem())
+	}
+
+	return nil
+}
+
+// envLeaves returns the names of the environment variables overriding the settings
+// of the struct, relatively to the struct itself.
+func envLeaves(t reflect.Type, path []string) (leaves []string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fieldPath := append(path[:len(path):len(path)], yamlName(f))
+
+		if f.Type.Kind() == reflect.Struct {
+			leaves = append(leaves, envLeaves(f.Type, fieldPath)...)
+		} else {
+			leaves = append(leaves, envName(fieldPath))
+		}
+	}
+
+	return
+}
+
+// setEnvValue parses the value of the environment variable according to the type of the setting.
+func setEnvValue(v reflect.Value, value string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid unsigned integer %q", value)
+		}
+
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q", value)
+		}
+
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
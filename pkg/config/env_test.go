@@ -0,0 +1,50 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigApplyEnv(t *testing.T) {
+	cfg := New()
+	cfg.Sources = map[string]Source{"Foo": {Enabled: true}}
+
+	assert.NoError(t, cfg.ApplyEnv([]string{
+		"MRE_PULL_METRICS_INTERVAL_SECONDS=10",
+		"MRE_PULL_METRICS_ON_INIT=false",
+		"MRE_LOG_LEVEL=debug",
+		"MRE_CLIENTS_MEND_RENOVATE_TOKEN=foo=bar",
+		"MRE_SOURCES_FOO_ENABLED=false",
+		"MRE_SOURCES_MEND_RENOVATE_ENABLED=false",
+		"MRE_UNKNOWN=foo",
+		"PATH=/bin",
+	}))
+
+	expected := New()
+	expected.Pull.Metrics.IntervalSeconds = 10
+	expected.Pull.Metrics.OnInit = false
+	expected.Log.Level = "debug"
+	expected.Clients.MendRenovate.Token = "foo=bar"
+	expected.Sources = map[string]Source{
+		"Foo":           {Enabled: false},
+		"mend_renovate": {Enabled: false},
+	}
+
+	assert.Equal(t, expected, cfg)
+
+	// New entries get the default values
+	cfg = New()
+	assert.NoError(t, cfg.ApplyEnv([]string{"MRE_SOURCES_FOO_UNKNOWN=1", "MRE_SOURCES_BAR_ENABLED=true"}))
+	assert.Equal(t, map[string]Source{"bar": {Enabled: true}}, cfg.Sources)
+
+	cfg = New()
+	assert.EqualError(t,
+		cfg.ApplyEnv([]string{"MRE_SERVER_WEBHOOK_DEBOUNCE_SECONDS=5s"}),
+		`MRE_SERVER_WEBHOOK_DEBOUNCE_SECONDS: invalid integer "5s"`,
+	)
+	assert.EqualError(t,
+		cfg.ApplyEnv([]string{"MRE_SERVER_ENABLE_PPROF=maybe"}),
+		`MRE_SERVER_ENABLE_PPROF: invalid boolean "maybe"`,
+	)
+}
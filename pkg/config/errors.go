@@ -0,0 +1,266 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/go-playground/validator/v10"
+	"gopkg.in/yaml.v3"
+)
+
+// Error is a human readable config error, located within the config file when possible.
+type Error struct {
+	// Path of the setting in the config file, eg: server.webhook.secret_token
+	Path string
+
+	// Line and Column of the setting in the config file, or of its closest parent
+	// when the setting is not set. They are 0 when unknown
+	Line   int
+	Column int
+
+	Message string
+}
+
+// Error implements error.
+func (e Error) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d: %s", e.Line, e.Description())
+	}
+
+	return e.Description()
+}
+
+// Description returns the error without its location.
+func (e Error) Description() string {
+	if e.Path != "" {
+		return e.Path + " " + e.Message
+	}
+
+	return e.Message
+}
+
+// yamlErrorLine matches the position reported by the YAML decoder.
+var yamlErrorLine = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// tomlErrorLine matches the position reported by the TOML decoder, which is already known.
+var tomlErrorLine = regexp.MustCompile(`^toml: line \d+(?: \(last key "[^"]*"\))?: `)
+
+// Explain converts the errors returned by Parse and Config.Validate into human
+// readable ones, located within the content of the config file when its format allows it.
+func Explain(err error, f Format, content []byte) (errs []Error) {
+	var (
+		unknownKeys    UnknownKeysError
+		configErr      Error
+		typeErr        *yaml.TypeError
+		tomlErr        toml.ParseError
+		validationErrs validator.ValidationErrors
+	)
+
+	switch {
+	case errors.As(err, &validationErrs):
+		// The content has already been parsed successfully
+		root, _ := parseNode(f, content)
+		if root == nil {
+			root = &yaml.Node{}
+		}
+
+		for _, fe := range validationErrs {
+			errs = append(errs, explainFieldError(fe, root))
+		}
+	case errors.As(err, &unknownKeys):
+		errs = append(errs, unknownKeys...)
+	case errors.As(err, &configErr):
+		if configErr.Line == 0 && configErr.Path != "" {
+			if root, _ := parseNode(f, content); root != nil {
+				if node, found := lookupNode(root, splitPath(configErr.Path)); found {
+					configErr.Line, configErr.Column = node.Line, node.Column
+				}
+			}
+		}
+
+		errs = append(errs, configErr)
+	case errors.As(err, &typeErr):
+		for _, msg := range typeErr.Errors {
+			errs = append(errs, explainYAMLError(msg))
+		}
+	case errors.As(err, &tomlErr):
+		errs = append(errs, Error{
+			Line:    tomlErr.Position.Line,
+			Column:  tomlErr.Position.Start - bytes.LastIndexByte(content[:tomlErr.Position.Start], '\n'),
+			Message: tomlErrorLine.ReplaceAllString(tomlErr.Error(), ""),
+		})
+	default:
+		errs = append(errs, explainYAMLError(err.Error()))
+	}
+
+	return
+}
+
+func explainYAMLError(msg string) Error {
+	m := yamlErrorLine.FindStringSubmatch(msg)
+	if m == nil {
+		return Error{Message: msg}
+	}
+
+	line, _ := strconv.Atoi(m[1])
+
+	return Error{Line: line, Message: m[2]}
+}
+
+func explainFieldError(fe validator.FieldError, root *yaml.Node) Error {
+	// The namespaces are prefixed with the name of the root struct
+	_, ns, _ := strings.Cut(fe.Namespace(), ".")
+	_, structNS, _ := strings.Cut(fe.StructNamespace(), ".")
+
+	e := Error{
+		Path:    ns,
+		Message: describeRule(fe.Tag(), fe.Param(), parentStruct(structNS)),
+	}
+
+	node, found := lookupNode(root, splitPath(ns))
+	if node != nil {
+		e.Line, e.Column = node.Line, node.Column
+	}
+
+	if !found {
+		e.Message += " (not set)"
+	}
+
+	return e
+}
+
+// describeRule returns a sentence describing a validation rule, parent is the
+// struct holding the field, used to name the fields referenced by the rule.
+func describeRule(tag, param string, parent reflect.Type) string {
+	switch tag {
+	case "required":
+		return "is required"
+	case "required_if":
+		fields := strings.Fields(param)
+
+		conditions := []string{}
+		for i := 0; i+1 < len(fields); i += 2 {
+			conditions = append(conditions, fmt.Sprintf("%s is %s", yamlFieldName(parent, fields[i]), fields[i+1]))
+		}
+
+		return "is required when " + strings.Join(conditions, " and ")
+	case "oneof":
+		return "must be one of: " + strings.Join(strings.Fields(param), ", ")
+	case "gte":
+		return "must be greater than or equal to " + param
+	case "gt":
+		return "must be greater than " + param
+	case "lte":
+		return "must be lower than or equal to " + param
+	case "lt":
+		return "must be lower than " + param
+	case "url":
+		return "must be a valid URL"
+	default:
+		if param != "" {
+			return fmt.Sprintf("must satisfy '%s=%s'", tag, param)
+		}
+
+		return fmt.Sprintf("must satisfy '%s'", tag)
+	}
+}
+
+// yamlName returns the key of the field in the config file.
+func yamlName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+	if name == "" {
+		return strings.ToLower(f.Name)
+	}
+
+	return name
+}
+
+// yamlFieldName returns the key in the config file of the field of the struct, its Go name is kept if not found.
+func yamlFieldName(t reflect.Type, name string) string {
+	if t != nil {
+		if f, ok := t.FieldByName(name); ok {
+			return yamlName(f)
+		}
+	}
+
+	return name
+}
+
+// parentStruct returns the type of the struct holding the field at the given
+// namespace, made of the Go names of the fields from the Config.
+func parentStruct(structNS string) reflect.Type {
+	t := reflect.TypeOf(Config{})
+
+	segments := splitPath(structNS)
+	for _, s := range segments[:max(0, len(segments)-1)] {
+		switch t.Kind() {
+		case reflect.Struct:
+			f, ok := t.FieldByName(s)
+			if !ok {
+				return nil
+			}
+
+			t = f.Type
+		case reflect.Map:
+			t = t.Elem()
+		default:
+			return nil
+		}
+	}
+
+	return t
+}
+
+// splitPath splits a namespace into its segments, the map keys being segments of their own: sources[foo].enabled.
+func splitPath(ns string) (segments []string) {
+	for _, s := range strings.Split(ns, ".") {
+		for {
+			name, key, ok := strings.Cut(s, "[")
+			if !ok {
+				break
+			}
+
+			segments = append(segments, name)
+			s = strings.TrimSuffix(key, "]")
+		}
+
+		segments = append(segments, s)
+	}
+
+	return
+}
+
+// lookupNode returns the key node at the given path, or the one of its closest
+// parent along with found set to false if the path is not set.
+func lookupNode(root *yaml.Node, path []string) (node *yaml.Node, found bool) {
+	current := root
+	if current.Kind == yaml.DocumentNode && len(current.Content) > 0 {
+		current = current.Content[0]
+	}
+
+segments:
+	for _, s := range path {
+		if current.Kind != yaml.MappingNode {
+			return node, false
+		}
+
+		for i := 0; i+1 < len(current.Content); i += 2 {
+			if current.Content[i].Value == s {
+				node = current.Content[i]
+				current = current.Content[i+1]
+
+				continue segments
+			}
+		}
+
+		return node, false
+	}
+
+	return node, true
+}
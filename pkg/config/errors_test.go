@@ -0,0 +1,105 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplainValidationErrors(t *testing.T) {
+	content := []byte(`---
+log:
+  level: verbose
+
+server:
+  webhook:
+    enabled: true
+
+sources:
+  other:
+    enabled: false
+`)
+
+	cfg, err := Parse(FormatYAML, content)
+	require.NoError(t, err)
+
+	err = cfg.Validate()
+	require.Error(t, err)
+
+	assert.Equal(t, []Error{
+		{
+			Path:    "log.level",
+			Line:    3,
+			Column:  3,
+			Message: "must be one of: trace, debug, info, warning, error, fatal, panic",
+		},
+		{
+			Path:    "server.webhook.secret_token",
+			Line:    6,
+			Column:  3,
+			Message: "is required when enabled is true (not set)",
+		},
+	}, Explain(err, FormatYAML, content))
+}
+
+func TestExplainYAMLErrors(t *testing.T) {
+	content := []byte("pull:\n  metrics:\n    interval_seconds: abc\n")
+
+	_, err := Parse(FormatYAML, content)
+	require.Error(t, err)
+
+	errs := Explain(err, FormatYAML, content)
+	require.Len(t, errs, 1)
+	assert.Equal(t, 3, errs[0].Line)
+	assert.Equal(t, "line 3: cannot unmarshal !!str `abc` into int", errs[0].Error())
+
+	content = []byte("pull: [\n")
+
+	_, err = Parse(FormatYAML, content)
+	require.Error(t, err)
+
+	errs = Explain(err, FormatYAML, content)
+	require.Len(t, errs, 1)
+	assert.Equal(t, 1, errs[0].Line)
+}
+
+func TestExplainTOMLErrors(t *testing.T) {
+	content := []byte("[pull.metrics]\ninterval_seconds = 4\non_init = nope\n")
+
+	_, err := Parse(FormatTOML, content)
+	require.Error(t, err)
+
+	errs := Explain(err, FormatTOML, content)
+	require.Len(t, errs, 1)
+	assert.Equal(t, 3, errs[0].Line)
+	assert.Equal(t, 11, errs[0].Column)
+
+	// The settings are not located
+	content = []byte("[server.webhook]\nenabled = true\n")
+
+	cfg, err := Parse(FormatTOML, content)
+	require.NoError(t, err)
+
+	errs = Explain(cfg.Validate(), FormatTOML, content)
+	require.Len(t, errs, 1)
+	assert.Equal(t, Error{Path: "server.webhook.secret_token", Message: "is required when enabled is true (not set)"}, errs[0])
+}
+
+func TestExplainSecretFileErrors(t *testing.T) {
+	content := []byte("clients:\n  mend_renovate:\n    token_file: /does/not/exist\n")
+
+	cfg, err := Parse(FormatYAML, content)
+	require.NoError(t, err)
+
+	errs := Explain(cfg.ReadSecretFiles(), FormatYAML, content)
+	require.Len(t, errs, 1)
+	assert.Equal(t, 3, errs[0].Line)
+	assert.Equal(t, 5, errs[0].Column)
+	assert.Equal(t, "clients.mend_renovate.token_file cannot be read: open /does/not/exist: no such file or directory", errs[0].Description())
+}
+
+func TestSplitPath(t *testing.T) {
+	assert.Equal(t, []string{"server", "webhook", "enabled"}, splitPath("server.webhook.enabled"))
+	assert.Equal(t, []string{"sources", "foo", "enabled"}, splitPath("sources[foo].enabled"))
+}
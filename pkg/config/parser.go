@@ -1,10 +1,17 @@
 package config
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
 
+	"github.com/BurntSushi/toml"
 	"gopkg.in/yaml.v3"
 )
 
@@ -14,11 +21,58 @@ type Format uint8
 const (
 	// FormatYAML represents a Config written in yaml format.
 	FormatYAML Format = iota
+
+	// FormatJSON represents a Config written in json format.
+	FormatJSON
+
+	// FormatTOML represents a Config written in toml format.
+	FormatTOML
 )
 
+// envReference matches the references to environment variables, eg: ${REDIS_URL}.
+// They can be escaped by doubling the $ sign, eg: $${NOT_REPLACED}.
+var envReference = regexp.MustCompile(`\$(\$?)\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// ParseOption alters the way the config gets parsed.
+type ParseOption func(*parseOptions)
+
+type parseOptions struct {
+	allowUnknownKeys  bool
+	keepEnvReferences bool
+}
+
+// AllowUnknownKeys ignores the keys which do not match any setting instead of
+// failing, eg: to read a config written for a more recent version.
+func AllowUnknownKeys() ParseOption {
+	return func(o *parseOptions) {
+		o.allowUnknownKeys = true
+	}
+}
+
+// KeepEnvReferences leaves the references to environment variables found in the
+// values as is, eg: to read a config whose values have already been expanded.
+func KeepEnvReferences() ParseOption {
+	return func(o *parseOptions) {
+		o.keepEnvReferences = true
+	}
+}
+
+// UnknownKeysError lists the keys which do not match any setting.
+type UnknownKeysError []Error
+
+// Error implements error.
+func (e UnknownKeysError) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
 // ParseFile reads the content of a file and attempt to unmarshal it
 // into a Config.
-func ParseFile(filename string) (c Config, err error) {
+func ParseFile(filename string, opts ...ParseOption) (c Config, err error) {
 	var (
 		t         Format
 		fileBytes []byte
@@ -37,29 +91,338 @@ func ParseFile(filename string) (c Config, err error) {
 	}
 
 	// Parse the content and return Config
-	return Parse(t, fileBytes)
+	return Parse(t, fileBytes, opts...)
 }
 
 // Parse unmarshal provided bytes with given ConfigType into a Config object.
-func Parse(f Format, bytes []byte) (cfg Config, err error) {
+// The content is applied on top of the default values, so that the settings
+// explicitly set to their zero value are kept as is. The references to
+// environment variables found in the values, eg: ${REDIS_URL}, are replaced
+// by their content unless kept. The keys which do not match any setting are reported as an
+// UnknownKeysError unless allowed.
+func Parse(f Format, bytes []byte, opts ...ParseOption) (cfg Config, err error) {
+	o := parseOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	cfg = New()
+
+	var (
+		content []byte
+		root    = &yaml.Node{}
+	)
+
+	if content, err = yamlContent(f, bytes); err == nil {
+		err = yaml.Unmarshal(content, root)
+	}
+
+	if err == nil && !o.allowUnknownKeys {
+		if unknownKeys := checkKeys(content, root); len(unknownKeys) > 0 {
+			err = unknownKeys
+		}
+	}
+
+	// The locations within the content converted from TOML are meaningless
+	if f == FormatTOML {
+		unlocate(root)
+
+		var unknownKeys UnknownKeysError
+		if errors.As(err, &unknownKeys) {
+			for i := range unknownKeys {
+				unknownKeys[i].Line, unknownKeys[i].Column = 0, 0
+			}
+		}
+	}
+
+	if err == nil && !o.keepEnvReferences {
+		err = expandEnv(root)
+	}
+
+	if err == nil {
+		err = root.Decode(&cfg)
+	}
+
+	if err != nil {
+		return Config{}, err
+	}
+
+	return
+}
+
+// parseNode returns the document tree of the content, whatever its format. The
+// trees built from TOML content are not located, as they are converted from YAML.
+func parseNode(f Format, bytes []byte) (*yaml.Node, error) {
+	content, err := yamlContent(f, bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	root := &yaml.Node{}
+	if err = yaml.Unmarshal(content, root); err != nil {
+		return nil, err
+	}
+
+	if f == FormatTOML {
+		unlocate(root)
+	}
+
+	return root, nil
+}
+
+// yamlContent returns the content in YAML, the TOML content is converted.
+func yamlContent(f Format, bytes []byte) ([]byte, error) {
 	switch f {
-	case FormatYAML:
-		err = yaml.Unmarshal(bytes, &cfg)
+	// JSON is a subset of YAML, parsing it as such allows to locate its errors
+	case FormatYAML, FormatJSON:
+		return bytes, nil
+	case FormatTOML:
+		var content map[string]interface{}
+		if err := toml.Unmarshal(bytes, &content); err != nil {
+			return nil, err
+		}
+
+		return yaml.Marshal(content)
+	default:
+		return nil, fmt.Errorf("unsupported config type '%+v'", f)
+	}
+}
+
+// unlocate removes the position of the nodes of the tree.
+func unlocate(n *yaml.Node) {
+	n.Line, n.Column = 0, 0
+
+	for _, c := range n.Content {
+		unlocate(c)
+	}
+}
+
+// unknownFieldError matches the errors reported by the YAML decoder for the keys which do not match any field.
+var unknownFieldError = regexp.MustCompile(`^line (\d+): field (.*) not found in type `)
+
+// checkKeys returns the keys of the YAML content which do not match any setting. They are reported
+// by the decoder, the errors are only located within the tree and given a hint about the setting the
+// closest to the key, if any.
+func checkKeys(content []byte, root *yaml.Node) (unknownKeys UnknownKeysError) {
+	cfg := New()
+
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	dec.KnownFields(true)
+
+	// The other errors are reported when decoding the tree, once the references to environment variables are replaced
+	var typeErr *yaml.TypeError
+	if !errors.As(dec.Decode(&cfg), &typeErr) {
+		return
+	}
+
+	// The keys of the anchored mappings are reported every time they are referenced, all of their
+	// occurrences get located at once
+	reported := make(map[string]bool)
+
+	for _, msg := range typeErr.Errors {
+		m := unknownFieldError.FindStringSubmatch(msg)
+		if m == nil || reported[m[1]+":"+m[2]] {
+			continue
+		}
+
+		reported[m[1]+":"+m[2]] = true
+
+		line, _ := strconv.Atoi(m[1])
+
+		errs := locateKey(root, reflect.TypeOf(cfg), nil, line, m[2])
+		if len(errs) == 0 {
+			errs = append(errs, Error{Path: m[2], Line: line, Message: "is not a known setting"})
+		}
+
+		unknownKeys = append(unknownKeys, errs...)
+	}
+
+	return
+}
+
+// locateKey returns the errors of the occurrences of the key found at the given line, amongst the keys
+// of the tree which do not match any field of their struct.
+func locateKey(n *yaml.Node, t reflect.Type, path []string, line int, key string) (errs UnknownKeysError) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		for _, c := range n.Content {
+			errs = append(errs, locateKey(c, t, path, line, key)...)
+		}
+
+		return
+	case yaml.AliasNode:
+		return locateKey(n.Alias, t, path, line, key)
+	case yaml.MappingNode:
+		// Looked up below, amongst the fields of the type
 	default:
-		err = fmt.Errorf("unsupported config type '%+v'", f)
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Map:
+		// The entries are named the way the validator does, eg: sources[foo]
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			entryPath := append(path[:len(path)-1:len(path)-1], fmt.Sprintf("%s[%s]", path[len(path)-1], n.Content[i].Value))
+			errs = append(errs, locateKey(n.Content[i+1], t.Elem(), entryPath, line, key)...)
+		}
+	case reflect.Struct:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+
+			// Merged mappings hold the fields of the same struct
+			if k.Value == "<<" {
+				errs = append(errs, locateKey(v, t, path, line, key)...)
+
+				continue
+			}
+
+			fieldPath := append(path[:len(path):len(path)], k.Value)
+
+			f, known := fieldByYAMLName(t, k.Value)
+			if !known && k.Line == line && k.Value == key {
+				e := Error{
+					Path:    strings.Join(fieldPath, "."),
+					Line:    k.Line,
+					Column:  k.Column,
+					Message: "is not a known setting",
+				}
+
+				if s := closestField(k.Value, t); s != "" {
+					e.Message += fmt.Sprintf(", did you mean %s?", s)
+				}
+
+				errs = append(errs, e)
+
+				continue
+			}
+
+			if known {
+				errs = append(errs, locateKey(v, f.Type, fieldPath, line, key)...)
+			}
+		}
 	}
 
 	return
 }
 
+// fieldByYAMLName returns the field of the struct with the given key in the config file.
+func fieldByYAMLName(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		if yamlName(t.Field(i)) == name {
+			return t.Field(i), true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+// closestField returns the key of the field of the struct the closest to the given one, if close
+// enough to be a typo: at most 2 edits away, and one edit every 4 characters.
+func closestField(key string, t reflect.Type) (closest string) {
+	best := min(3, len(key)/4+1)
+
+	for i := 0; i < t.NumField(); i++ {
+		k := yamlName(t.Field(i))
+		if d := editDistance(key, k); d < best || (d == best && closest != "" && k < closest) {
+			best, closest = d, k
+		}
+	}
+
+	return
+}
+
+// editDistance returns the Levenshtein distance between the strings.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous = current
+	}
+
+	return previous[len(b)]
+}
+
+// expandEnv replaces the references to environment variables found in the values of the tree,
+// an error is returned if any of the referenced variables is not defined.
+func expandEnv(n *yaml.Node) error {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		var err error
+
+		value := envReference.ReplaceAllStringFunc(n.Value, func(ref string) string {
+			m := envReference.FindStringSubmatch(ref)
+			if m[1] != "" {
+				return ref[1:]
+			}
+
+			v, ok := os.LookupEnv(m[2])
+			if !ok && err == nil {
+				err = Error{
+					Line:    n.Line,
+					Column:  n.Column,
+					Message: fmt.Sprintf("environment variable %s is not defined", m[2]),
+				}
+			}
+
+			return v
+		})
+		if err != nil {
+			return err
+		}
+
+		if value != n.Value {
+			n.Value = value
+
+			// Unquoted values are resolved again in order to be able to reference numbers or booleans
+			if n.Style == 0 {
+				n.Tag = ""
+			}
+		}
+	case yaml.MappingNode:
+		// Only the values are expanded, not the keys
+		for i := 1; i < len(n.Content); i += 2 {
+			if err := expandEnv(n.Content[i]); err != nil {
+				return err
+			}
+		}
+	default:
+		for _, c := range n.Content {
+			if err := expandEnv(c); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
 // GetTypeFromFileExtension returns the ConfigType based upon the extension of
 // the file.
 func GetTypeFromFileExtension(filename string) (f Format, err error) {
 	switch ext := filepath.Ext(filename); ext {
 	case ".yml", ".yaml":
 		f = FormatYAML
+	case ".json":
+		f = FormatJSON
+	case ".toml":
+		f = FormatTOML
 	default:
-		err = fmt.Errorf("unsupported config type '%s', expected .y(a)ml", ext)
+		err = fmt.Errorf("unsupported config type '%s', expected .y(a)ml, .json or .toml", ext)
 	}
 
 	return
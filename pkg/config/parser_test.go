@@ -1,11 +1,10 @@
 package config
 
 import (
-	"os"
-	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestParseFileInvalidPath(t *testing.T) {
@@ -14,44 +13,446 @@ func TestParseFileInvalidPath(t *testing.T) {
 	assert.Equal(t, Config{}, cfg)
 }
 
-func TestParseInvalidYaml(t *testing.T) {
-	cfg, err := Parse(FormatYAML, []byte("invalid_yaml"))
-	assert.Error(t, err)
-	assert.Equal(t, Config{}, cfg)
+const validConfigYAML = `---
+log:
+  level: trace
+  format: json
+
+opentelemetry:
+  grpc_endpoint: otlp-collector:4317
+  service_name_key: mend-renovate-ce-ee-exporter
+
+server:
+  enable_pprof: true
+  listen_address: :1025
+
+  metrics:
+    enabled: false
+    enable_openmetrics_encoding: false
+
+  webhook:
+    enabled: true
+    secret_token: secret
+    debounce_seconds: 2
+    relay:
+      enabled: true
+      url: http://renovate:8080/webhook
+      timeout_seconds: 5
+      max_buffered_deliveries: 100
+      retry_interval_seconds: 60
+
+  health:
+    max_pull_intervals_without_success: 5
+
+  admin:
+    enabled: true
+    token: admin
+
+redis:
+  url: "redis://popopo:1337"
+
+scheduler:
+  shutdown_timeout_seconds: 10
+
+pull:
+  metrics:
+    on_init: false
+    scheduled: false
+    interval_seconds: 4
+
+garbage_collect:
+  metrics:
+    on_init: true
+    scheduled: false
+    interval_seconds: 4
+
+renovate:
+  bot_username: renovate-bot
+  branch_prefix: deps/
+
+sources:
+  mend_renovate:
+    enabled: false
+  other: {}
+`
+
+const validConfigJSON = `{
+  "log": {
+    "level": "trace",
+    "format": "json"
+  },
+  "opentelemetry": {
+    "grpc_endpoint": "otlp-collector:4317",
+    "service_name_key": "mend-renovate-ce-ee-exporter"
+  },
+  "server": {
+    "enable_pprof": true,
+    "listen_address": ":1025",
+    "metrics": {
+      "enabled": false,
+      "enable_openmetrics_encoding": false
+    },
+    "webhook": {
+      "enabled": true,
+      "secret_token": "secret",
+      "debounce_seconds": 2,
+      "relay": {
+        "enabled": true,
+        "url": "http://renovate:8080/webhook",
+        "timeout_seconds": 5,
+        "max_buffered_deliveries": 100,
+        "retry_interval_seconds": 60
+      }
+    },
+    "health": {
+      "max_pull_intervals_without_success": 5
+    },
+    "admin": {
+      "enabled": true,
+      "token": "admin"
+    }
+  },
+  "redis": {
+    "url": "redis://popopo:1337"
+  },
+  "scheduler": {
+    "shutdown_timeout_seconds": 10
+  },
+  "pull": {
+    "metrics": {
+      "on_init": false,
+      "scheduled": false,
+      "interval_seconds": 4
+    }
+  },
+  "garbage_collect": {
+    "metrics": {
+      "on_init": true,
+      "scheduled": false,
+      "interval_seconds": 4
+    }
+  },
+  "renovate": {
+    "bot_username": "renovate-bot",
+    "branch_prefix": "deps/"
+  },
+  "sources": {
+    "mend_renovate": {
+      "enabled": false
+    },
+    "other": {}
+  }
 }
+`
+
+const validConfigTOML = `[log]
+level = "trace"
+format = "json"
+
+[opentelemetry]
+grpc_endpoint = "otlp-collector:4317"
+service_name_key = "mend-renovate-ce-ee-exporter"
+
+[server]
+enable_pprof = true
+listen_address = ":1025"
+
+[server.metrics]
+enabled = false
+enable_openmetrics_encoding = false
+
+[server.webhook]
+enabled = true
+secret_token = "secret"
+debounce_seconds = 2
+
+[server.webhook.relay]
+enabled = true
+url = "http://renovate:8080/webhook"
+timeout_seconds = 5
+max_buffered_deliveries = 100
+retry_interval_seconds = 60
+
+[server.health]
+max_pull_intervals_without_success = 5
+
+[server.admin]
+enabled = true
+token = "admin"
+
+[redis]
+url = "redis://popopo:1337"
+
+[scheduler]
+shutdown_timeout_seconds = 10
+
+[pull.metrics]
+on_init = false
+scheduled = false
+interval_seconds = 4
+
+[garbage_collect.metrics]
+on_init = true
+scheduled = false
+interval_seconds = 4
 
-func TestParseValidYaml(t *testing.T) {
-	yamlConfig, err := os.ReadFile(filepath.Clean("./testdata/ValidConfig.yaml"))
-	assert.NoError(t, err)
+[renovate]
+bot_username = "renovate-bot"
+branch_prefix = "deps/"
 
-	cfg, err := Parse(FormatYAML, []byte(yamlConfig))
-	assert.NoError(t, err)
+[sources.mend_renovate]
+enabled = false
 
-	// Create a config from the default values
-	xcfg := New()
-	xcfg.Log.Level = "trace"
-	xcfg.Log.Format = "json"
+[sources.other]
+`
 
-	xcfg.OpenTelemetry.GRPCEndpoint = "otlp-collector:4317"
-	xcfg.OpenTelemetry.ServiceNameKey = "mend-renovate-ce-ee-exporter"
+// validConfig returns the config described by the validConfig* fixtures.
+func validConfig() (cfg Config) {
+	cfg = New()
+	cfg.Log.Level = "trace"
+	cfg.Log.Format = "json"
+
+	cfg.OpenTelemetry.GRPCEndpoint = "otlp-collector:4317"
+	cfg.OpenTelemetry.ServiceNameKey = "mend-renovate-ce-ee-exporter"
+
+	cfg.Server.EnablePprof = true
+	cfg.Server.ListenAddress = ":1025"
+	cfg.Server.Metrics.Enabled = false
+	cfg.Server.Metrics.EnableOpenmetricsEncoding = false
+	cfg.Server.Webhook.Enabled = true
+	cfg.Server.Webhook.SecretToken = "secret"
+	cfg.Server.Webhook.DebounceSeconds = 2
+	cfg.Server.Webhook.Relay.Enabled = true
+	cfg.Server.Webhook.Relay.URL = "http://renovate:8080/webhook"
+	cfg.Server.Webhook.Relay.TimeoutSeconds = 5
+	cfg.Server.Webhook.Relay.MaxBufferedDeliveries = 100
+	cfg.Server.Webhook.Relay.RetryIntervalSeconds = 60
+	cfg.Server.Health.MaxPullIntervalsWithoutSuccess = 5
+	cfg.Server.Admin.Enabled = true
+	cfg.Server.Admin.Token = "admin"
+
+	cfg.Redis.URL = "redis://popopo:1337"
+
+	cfg.Scheduler.ShutdownTimeoutSeconds = 10
+
+	cfg.Pull.Metrics.OnInit = false
+	cfg.Pull.Metrics.Scheduled = false
+	cfg.Pull.Metrics.IntervalSeconds = 4
+
+	cfg.GarbageCollect.Metrics.OnInit = true
+	cfg.GarbageCollect.Metrics.Scheduled = false
+	cfg.GarbageCollect.Metrics.IntervalSeconds = 4
+
+	cfg.Renovate.BotUsername = "renovate-bot"
+	cfg.Renovate.BranchPrefix = "deps/"
+
+	cfg.Sources = map[string]Source{
+		"mend_renovate": {Enabled: false},
+		"other":         {Enabled: true},
+	}
+
+	return
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   Format
+		content  string
+		expected Config
+		err      string
+	}{
+		{
+			name:     "valid yaml",
+			format:   FormatYAML,
+			content:  validConfigYAML,
+			expected: validConfig(),
+		},
+		{
+			name:     "valid json",
+			format:   FormatJSON,
+			content:  validConfigJSON,
+			expected: validConfig(),
+		},
+		{
+			name:     "valid toml",
+			format:   FormatTOML,
+			content:  validConfigTOML,
+			expected: validConfig(),
+		},
+		{
+			name:     "empty",
+			format:   FormatYAML,
+			content:  "",
+			expected: New(),
+		},
+		{
+			name:    "invalid yaml",
+			format:  FormatYAML,
+			content: "invalid_yaml",
+			err:     "yaml: unmarshal errors:\n  line 1: cannot unmarshal !!str `invalid...` into config.Config",
+		},
+		{
+			name:    "invalid type",
+			format:  FormatYAML,
+			content: "pull:\n  metrics:\n    interval_seconds: abc\n",
+			err:     "yaml: unmarshal errors:\n  line 3: cannot unmarshal !!str `abc` into int",
+		},
+		{
+			name:    "unknown key",
+			format:  FormatYAML,
+			content: "pull:\n  metrics:\n    intervall_seconds: 10\n",
+			err:     "line 3: pull.metrics.intervall_seconds is not a known setting, did you mean interval_seconds?",
+		},
+		{
+			name:    "unknown keys",
+			format:  FormatYAML,
+			content: "foo: bar\nsources:\n  mend_renovate:\n    enable: false\n",
+			err: "line 1: foo is not a known setting\n" +
+				"line 4: sources[mend_renovate].enable is not a known setting, did you mean enabled?",
+		},
+		{
+			name:    "unknown key within an anchor",
+			format:  FormatYAML,
+			content: "pull:\n  metrics: &metrics\n    on_init: true\n    foo: bar\ngarbage_collect:\n  metrics: *metrics\n",
+			err:     "line 4: pull.metrics.foo is not a known setting\nline 4: garbage_collect.metrics.foo is not a known setting",
+		},
+		{
+			name:    "merged mapping",
+			format:  FormatYAML,
+			content: "pull:\n  metrics:\n    <<: &metrics\n      on_init: false\n      scheduled: false\ngarbage_collect:\n  metrics:\n    <<: *metrics\n",
+			expected: func() Config {
+				c := New()
+				c.Pull.Metrics.OnInit = false
+				c.Pull.Metrics.Scheduled = false
+				c.GarbageCollect.Metrics.Scheduled = false
+
+				return c
+			}(),
+		},
+		{
+			name:    "unknown key within a merged mapping",
+			format:  FormatYAML,
+			content: "pull:\n  metrics:\n    <<: &metrics\n      on_init: false\n      foo: bar\ngarbage_collect:\n  metrics:\n    <<: *metrics\n",
+			err:     "line 5: pull.metrics.foo is not a known setting\nline 5: garbage_collect.metrics.foo is not a known setting",
+		},
+		{
+			name:    "unknown key in json",
+			format:  FormatJSON,
+			content: `{"server": {"listen_adress": ":8080"}}`,
+			err:     "line 1: server.listen_adress is not a known setting, did you mean listen_address?",
+		},
+		{
+			name:    "unknown key in toml",
+			format:  FormatTOML,
+			content: "[server]\nlisten_adress = \":8080\"\n",
+			err:     "server.listen_adress is not a known setting, did you mean listen_address?",
+		},
+		{
+			name:    "unknown source key in toml",
+			format:  FormatTOML,
+			content: "[sources.mend_renovate]\nenable = false\n",
+			err:     "sources[mend_renovate].enable is not a known setting, did you mean enabled?",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := Parse(tc.format, []byte(tc.content))
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				assert.Equal(t, Config{}, cfg)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, cfg)
+		})
+	}
+}
+
+func TestParseUnknownKeys(t *testing.T) {
+	content := []byte("pull:\n  metrics:\n    intervall_seconds: 10\n    interval_seconds: 20\n")
+
+	_, err := Parse(FormatYAML, content)
+
+	var unknownKeys UnknownKeysError
+	require.ErrorAs(t, err, &unknownKeys)
+	assert.Equal(t, UnknownKeysError{{
+		Path:    "pull.metrics.intervall_seconds",
+		Line:    3,
+		Column:  5,
+		Message: "is not a known setting, did you mean interval_seconds?",
+	}}, unknownKeys)
+	assert.Equal(t, []Error(unknownKeys), Explain(err, FormatYAML, content))
+
+	// They can be ignored in order to read configs written for more recent versions
+	cfg, err := Parse(FormatYAML, content, AllowUnknownKeys())
+	require.NoError(t, err)
+	assert.Equal(t, 20, cfg.Pull.Metrics.IntervalSeconds)
+}
+
+func TestValidConfigSources(t *testing.T) {
+	cfg := validConfig()
+	assert.False(t, cfg.SourceEnabled("mend_renovate"))
+	assert.True(t, cfg.SourceEnabled("other"))
+	assert.True(t, cfg.SourceEnabled("unknown"))
+}
+
+func TestGetTypeFromFileExtension(t *testing.T) {
+	for filename, expected := range map[string]Format{
+		"foo.yml":  FormatYAML,
+		"foo.yaml": FormatYAML,
+		"foo.json": FormatJSON,
+		"foo.toml": FormatTOML,
+	} {
+		f, err := GetTypeFromFileExtension(filename)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, f, filename)
+	}
+
+	_, err := GetTypeFromFileExtension("foo.ini")
+	assert.EqualError(t, err, "unsupported config type '.ini', expected .y(a)ml, .json or .toml")
+}
 
-	xcfg.Server.EnablePprof = true
-	xcfg.Server.ListenAddress = ":1025"
-	xcfg.Server.Metrics.Enabled = false
-	xcfg.Server.Metrics.EnableOpenmetricsEncoding = false
-	xcfg.Server.Webhook.Enabled = true
-	xcfg.Server.Webhook.SecretToken = "secret"
+func TestParseEnvReferences(t *testing.T) {
+	t.Setenv("MRE_TEST_TOKEN", "s3cr3t: #1")
+	t.Setenv("MRE_TEST_INTERVAL", "10")
+	t.Setenv("MRE_TEST_EMPTY", "")
 
-	xcfg.Redis.URL = "redis://popopo:1337"
+	cfg, err := Parse(FormatYAML, []byte(`
+clients:
+  mend_renovate:
+    url: https://${MRE_TEST_EMPTY}renovate:8080
+    token: ${MRE_TEST_TOKEN}
+pull:
+  metrics:
+    interval_seconds: ${MRE_TEST_INTERVAL}
+renovate:
+  bot_username: "${MRE_TEST_INTERVAL}"
+  branch_prefix: $${MRE_TEST_TOKEN}/
+`))
+	require.NoError(t, err)
+	assert.Equal(t, "https://renovate:8080", cfg.Clients.MendRenovate.URL)
+	assert.Equal(t, "s3cr3t: #1", cfg.Clients.MendRenovate.Token)
+	assert.Equal(t, 10, cfg.Pull.Metrics.IntervalSeconds)
+	assert.Equal(t, "10", cfg.Renovate.BotUsername)
+	assert.Equal(t, "${MRE_TEST_TOKEN}/", cfg.Renovate.BranchPrefix)
 
-	xcfg.Pull.Metrics.OnInit = false
-	xcfg.Pull.Metrics.Scheduled = false
-	xcfg.Pull.Metrics.IntervalSeconds = 4
+	cfg, err = Parse(FormatTOML, []byte(`
+[pull.metrics]
+interval_seconds = "${MRE_TEST_INTERVAL}"
+`))
+	require.NoError(t, err)
+	assert.Equal(t, 10, cfg.Pull.Metrics.IntervalSeconds)
 
-	xcfg.GarbageCollect.Metrics.OnInit = true
-	xcfg.GarbageCollect.Metrics.Scheduled = false
-	xcfg.GarbageCollect.Metrics.IntervalSeconds = 4
+	_, err = Parse(FormatYAML, []byte("redis:\n  url: ${MRE_TEST_UNDEFINED}\n"))
+	assert.EqualError(t, err, "line 2: environment variable MRE_TEST_UNDEFINED is not defined")
 
-	// Test variable assignments
-	assert.Equal(t, xcfg, cfg)
+	// They can be kept in order to read configs whose values have already been expanded
+	cfg, err = Parse(FormatYAML, []byte("redis:\n  url: ${MRE_TEST_UNDEFINED}\nrenovate:\n  branch_prefix: $${MRE_TEST_TOKEN}/\n"), KeepEnvReferences())
+	require.NoError(t, err)
+	assert.Equal(t, "${MRE_TEST_UNDEFINED}", cfg.Redis.URL)
+	assert.Equal(t, "$${MRE_TEST_TOKEN}/", cfg.Renovate.BranchPrefix)
 }
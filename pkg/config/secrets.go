@@ -0,0 +1,36 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ReadSecretFiles sets the secrets which are not set from the files referenced
+// by their *_file counterpart, eg: clients.mend_renovate.token_file.
+func (c *Config) ReadSecretFiles() error {
+	for _, s := range []struct {
+		path  string
+		value *string
+		file  string
+	}{
+		{"clients.mend_renovate.token_file", &c.Clients.MendRenovate.Token, c.Clients.MendRenovate.TokenFile},
+		{"server.webhook.secret_token_file", &c.Server.Webhook.SecretToken, c.Server.Webhook.SecretTokenFile},
+		{"server.admin.token_file", &c.Server.Admin.Token, c.Server.Admin.TokenFile},
+		{"redis.url_file", &c.Redis.URL, c.Redis.URLFile},
+	} {
+		if s.file == "" || *s.value != "" {
+			continue
+		}
+
+		b, err := os.ReadFile(filepath.Clean(s.file))
+		if err != nil {
+			return Error{Path: s.path, Message: "cannot be read: " + err.Error()}
+		}
+
+		// Files tend to end with a newline which is not part of the secret
+		*s.value = strings.TrimSpace(string(b))
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigReadSecretFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	secret := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+		return path
+	}
+
+	cfg := New()
+	cfg.Clients.MendRenovate.TokenFile = secret("token", "foo\n")
+	cfg.Server.Webhook.SecretTokenFile = secret("secret_token", "bar")
+	cfg.Server.Admin.Token = "set"
+	cfg.Server.Admin.TokenFile = filepath.Join(dir, "ignored")
+	cfg.Redis.URLFile = secret("redis_url", "redis://:pass@redis:6379\n")
+
+	require.NoError(t, cfg.ReadSecretFiles())
+	assert.Equal(t, "foo", cfg.Clients.MendRenovate.Token)
+	assert.Equal(t, "bar", cfg.Server.Webhook.SecretToken)
+	assert.Equal(t, "set", cfg.Server.Admin.Token)
+	assert.Equal(t, "redis://:pass@redis:6379", cfg.Redis.URL)
+
+	cfg = New()
+	cfg.Redis.URLFile = filepath.Join(dir, "missing")
+
+	err := cfg.ReadSecretFiles()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "redis.url_file cannot be read: open ")
+}
@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/schemas"
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/store"
+)
+
+// Replica describes the current process to the other replicas.
+func (c *Controller) Replica() schemas.Replica {
+	lastTaskType, lastTaskAt := c.TaskController.Telemetry.LastExecuted()
+
+	return schemas.Replica{
+		UUID:         c.UUID.String(),
+		Version:      c.version,
+		Hostname:     c.hostname,
+		StartedAt:    c.startedAt,
+		Leader:       c.leader.Load(),
+		LastTaskType: lastTaskType,
+		LastTaskAt:   lastTaskAt,
+		RunningTasks: c.TaskController.Telemetry.RunningTasks(),
+	}
+}
+
+// IsLeader returns whether the process is the leader amongst the replicas.
+func (c *Controller) IsLeader() bool {
+	return c.leader.Load()
+}
+
+// Replicas returns the description of the processes currently running, including this one.
+func (c *Controller) Replicas(ctx context.Context) ([]schemas.Replica, error) {
+	if s, ok := c.Store.(*store.Redis); ok {
+		return s.Replicas(ctx)
+	}
+
+	return []schemas.Replica{c.Replica()}, nil
+}
+
+// Liveness tells whether the processes are still running. Besides the ones
+// publishing their replica, the ones which only set their keepalive, such as the
+// ones running older versions, are considered alive.
+type Liveness struct {
+	store store.Store
+	alive map[string]bool
+}
+
+// Liveness returns the liveness of the processes, given the replicas currently running.
+func (c *Controller) Liveness(replicas []schemas.Replica) *Liveness {
+	l := &Liveness{
+		store: c.Store,
+		alive: make(map[string]bool),
+	}
+
+	for _, r := range replicas {
+		l.alive[r.UUID] = true
+	}
+
+	return l
+}
+
+// Alive returns whether the process is still running, its keepalive is looked up once at most.
+func (l *Liveness) Alive(ctx context.Context, uuid string) (alive bool, err error) {
+	alive, checked := l.alive[uuid]
+	if checked {
+		return
+	}
+
+	if s, ok := l.store.(*store.Redis); ok {
+		if alive, err = s.KeepaliveExists(ctx, uuid); err != nil {
+			return
+		}
+	}
+
+	l.alive[uuid] = alive
+
+	return
+}
+
+// publishReplica refreshes the leadership of the process and lets the other
+// replicas know about it, the description expires after the ttl.
+func (c *Controller) publishReplica(ctx context.Context, ttl time.Duration) {
+	s := c.Store.(*store.Redis)
+
+	leader, err := s.AcquireLeadership(ctx, c.UUID.String(), ttl)
+	if err != nil {
+		log.WithContext(ctx).
+			WithError(err).
+			Warn("acquiring leadership")
+	}
+
+	if c.leader.Swap(leader) != leader {
+		log.WithField("leader", leader).Info("leadership changed")
+	}
+
+	if err = s.SetReplica(ctx, c.Replica(), ttl); err != nil {
+		log.WithContext(ctx).
+			WithError(err).
+			Warn("publishing replica")
+	}
+}
+
+// publishRunningTasks lets the other replicas know right away about the tasks
+// started and completed by the process, ahead of the next keepalive.
+func (c *Controller) publishRunningTasks(ctx context.Context) {
+	s, ok := c.Store.(*store.Redis)
+	if !ok {
+		return
+	}
+
+	if err := s.SetReplica(ctx, c.Replica(), redisKeepaliveTTL); err != nil {
+		log.WithContext(ctx).
+			WithError(err).
+			Warn("publishing replica running tasks")
+	}
+}
+
+// unpublishReplica removes the description of the process and gives up its leadership.
+func (c *Controller) unpublishReplica(ctx context.Context) {
+	s := c.Store.(*store.Redis)
+
+	if err := s.ReleaseLeadership(ctx, c.UUID.String()); err != nil {
+		log.WithContext(ctx).
+			WithError(err).
+			Warn("releasing leadership")
+	}
+
+	if err := s.DelReplica(ctx, c.UUID.String()); err != nil {
+		log.WithContext(ctx).
+			WithError(err).
+			Warn("removing replica")
+	}
+
+	c.leader.Store(false)
+}
@@ -1,6 +1,34 @@
 package controller
 
-import "github.com/prometheus/client_golang/prometheus"
+import (
+	"github.com/heptiolabs/healthcheck"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// NewGaugeVecCollector returns a RegistryCollector exporting a gauge vector.
+func NewGaugeVecCollector(opts prometheus.GaugeOpts, labelNames []string) RegistryCollector {
+	return RegistryCollector{
+		Collector: prometheus.NewGaugeVec(opts, labelNames),
+		Name:      prometheus.BuildFQName(opts.Namespace, opts.Subsystem, opts.Name),
+	}
+}
+
+// NewCounterVecCollector returns a RegistryCollector exporting a counter vector.
+func NewCounterVecCollector(opts prometheus.CounterOpts, labelNames []string) RegistryCollector {
+	return RegistryCollector{
+		Collector: prometheus.NewCounterVec(opts, labelNames),
+		Name:      prometheus.BuildFQName(opts.Namespace, opts.Subsystem, opts.Name),
+	}
+}
+
+// NewHistogramVecCollector returns a RegistryCollector exporting a histogram vector
+// populated from the store, see HistogramVec.
+func NewHistogramVecCollector(opts prometheus.HistogramOpts, labelNames []string) RegistryCollector {
+	return RegistryCollector{
+		Collector: NewHistogramVec(opts, labelNames),
+		Name:      prometheus.BuildFQName(opts.Namespace, opts.Subsystem, opts.Name),
+	}
+}
 
 // NewInternalCollectorCurrentlyQueuedTasksCount returns a new collector for the mre_currently_queued_tasks_count metric.
 func NewInternalCollectorCurrentlyQueuedTasksCount() prometheus.Collector {
@@ -34,3 +62,134 @@ func NewInternalCollectorMetricsCount() prometheus.Collector {
 		[]string{},
 	)
 }
+
+// NewInternalCollectorTaskDuration returns a new collector for the mre_task_duration_seconds metric.
+func NewInternalCollectorTaskDuration() prometheus.Collector {
+	return prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "mre_task_duration_seconds",
+			Help:    "Duration of the task executions",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"task_type", "outcome"},
+	)
+}
+
+// NewInternalCollectorTaskQueueWait returns a new collector for the mre_task_queue_wait_seconds metric.
+func NewInternalCollectorTaskQueueWait() prometheus.Collector {
+	return prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "mre_task_queue_wait_seconds",
+			Help:    "Time spent by the tasks in the queue before being handled",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"task_type"},
+	)
+}
+
+// NewInternalCollectorTaskSkipped returns a new collector for the mre_task_skipped_total metric.
+func NewInternalCollectorTaskSkipped() prometheus.Collector {
+	return prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mre_task_skipped_total",
+			Help: "Number of tasks which could not be scheduled",
+		},
+		[]string{"reason"},
+	)
+}
+
+// NewInternalCollectorHealthCheckStatus returns a new collector for the mre_health_check_status metric.
+func NewInternalCollectorHealthCheckStatus(name string, check healthcheck.Check) prometheus.Collector {
+	return prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Name:        "mre_health_check_status",
+			Help:        "Current health check status (0 indicates success, 1 indicates failure)",
+			ConstLabels: prometheus.Labels{"check": name},
+		},
+		func() float64 {
+			if check() == nil {
+				return 0
+			}
+
+			return 1
+		},
+	)
+}
+
+// NewInternalCollectorConfigLastReloadSuccess returns a new collector for the mre_config_last_reload_success metric.
+func NewInternalCollectorConfigLastReloadSuccess() prometheus.Collector {
+	return prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mre_config_last_reload_success",
+			Help: "Whether the last configuration reload attempt was successful",
+		},
+		[]string{},
+	)
+}
+
+// NewInternalCollectorConfigLastReloadSuccessTimestamp returns a new collector for the mre_config_last_reload_success_timestamp_seconds metric.
+func NewInternalCollectorConfigLastReloadSuccessTimestamp() prometheus.Collector {
+	return prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mre_config_last_reload_success_timestamp_seconds",
+			Help: "Timestamp of the last successful configuration reload",
+		},
+		[]string{},
+	)
+}
+
+// NewInternalCollectorWebhookRequests returns a new collector for the mre_webhook_requests_total metric.
+func NewInternalCollectorWebhookRequests() prometheus.Collector {
+	return prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mre_webhook_requests_total",
+			Help: "Number of webhook requests received",
+		},
+		[]string{"source", "event", "result"},
+	)
+}
+
+// NewInternalCollectorWebhookRelayReceived returns a new collector for the mre_webhook_relay_received_total metric.
+func NewInternalCollectorWebhookRelayReceived() prometheus.Collector {
+	return prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mre_webhook_relay_received_total",
+			Help: "Number of webhook deliveries to relay to Mend Renovate",
+		},
+		[]string{"source", "event", "repository"},
+	)
+}
+
+// NewInternalCollectorWebhookRelayForwardDuration returns a new collector for the mre_webhook_relay_forward_duration_seconds metric.
+func NewInternalCollectorWebhookRelayForwardDuration() prometheus.Collector {
+	return prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "mre_webhook_relay_forward_duration_seconds",
+			Help:    "Duration of the webhook deliveries forwarding to Mend Renovate",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"outcome"},
+	)
+}
+
+// NewInternalCollectorWebhookRelayForwardFailures returns a new collector for the mre_webhook_relay_forward_failures_total metric.
+func NewInternalCollectorWebhookRelayForwardFailures() prometheus.Collector {
+	return prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mre_webhook_relay_forward_failures_total",
+			Help: "Number of webhook deliveries which could not be forwarded to Mend Renovate",
+		},
+		[]string{"reason"},
+	)
+}
+
+// NewInternalCollectorWebhookRelayBufferedDeliveriesCount returns a new collector for the mre_webhook_relay_buffered_deliveries_count metric.
+func NewInternalCollectorWebhookRelayBufferedDeliveriesCount() prometheus.Collector {
+	return prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mre_webhook_relay_buffered_deliveries_count",
+			Help: "Number of webhook deliveries buffered whilst Mend Renovate is unavailable",
+		},
+		[]string{},
+	)
+}
@@ -0,0 +1,215 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vmihailenco/taskq/v4"
+
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/schemas"
+)
+
+// ErrUnknownTaskType is returned when acting upon a task type which is not registered.
+var ErrUnknownTaskType = errors.New("unknown task type")
+
+// TaskTypes returns the registered task types, sorted by name.
+func (c *Controller) TaskTypes() (taskTypes []schemas.TaskType) {
+	c.TaskController.TaskMap.Range(func(name string, _ *taskq.Task) bool {
+		taskTypes = append(taskTypes, schemas.TaskType(name))
+
+		return true
+	})
+
+	sort.Slice(taskTypes, func(i, j int) bool {
+		return taskTypes[i] < taskTypes[j]
+	})
+
+	return
+}
+
+func (c *Controller) checkTaskType(tt schemas.TaskType) error {
+	if c.TaskController.TaskMap.Get(string(tt)) == nil {
+		return ErrUnknownTaskType
+	}
+
+	return nil
+}
+
+// TriggerTask enqueues the task right away, it returns the reason for which
+// the task did not get scheduled or an empty string if it did.
+func (c *Controller) TriggerTask(ctx context.Context, tt schemas.TaskType) (skipReason string, err error) {
+	if err = c.checkTaskType(tt); err != nil {
+		return
+	}
+
+	log.WithContext(ctx).
+		WithField("task_type", tt).
+		Info("task triggered")
+
+	return c.ScheduleTask(ctx, tt, "_"), nil
+}
+
+// SetTaskSchedulingPaused suspends or resumes the periodic scheduling of the task,
+// across all the running processes. The task can still be triggered while paused.
+func (c *Controller) SetTaskSchedulingPaused(ctx context.Context, tt schemas.TaskType, paused bool) error {
+	if err := c.checkTaskType(tt); err != nil {
+		return err
+	}
+
+	if err := c.Store.SetTaskSchedulingPaused(ctx, tt, paused); err != nil {
+		return err
+	}
+
+	log.WithContext(ctx).
+		WithFields(
+			log.Fields{
+				"task_type": tt,
+				"paused":    paused,
+			},
+		).Info("task scheduling updated")
+
+	return nil
+}
+
+// taskSchedulingPaused returns whether the periodic scheduling of the task is suspended,
+// the task keeps being scheduled if this cannot be determined.
+func (c *Controller) taskSchedulingPaused(ctx context.Context, tt schemas.TaskType) bool {
+	status, err := c.Store.TaskSchedulingStatus(ctx, tt)
+	if err != nil {
+		log.WithContext(ctx).
+			WithField("task_type", tt).
+			WithError(err).
+			Warn("reading task scheduling status")
+
+		return false
+	}
+
+	if status.Paused {
+		c.TaskController.Telemetry.IncSkipped(TaskSkippedReasonPaused)
+		log.WithField("task_type", tt).
+			Debug("task scheduling paused, skipping scheduling of task..")
+	}
+
+	return status.Paused
+}
+
+// PurgeTaskQueue drops the tasks waiting in the queue. Their locks get released
+// as well, otherwise the purged tasks could not be scheduled again. The locks of
+// the tasks being executed by any of the replicas are kept, they get released once
+// completed. It returns the number of released locks.
+func (c *Controller) PurgeTaskQueue(ctx context.Context) (released int, err error) {
+	if err = c.TaskController.Queue.Purge(ctx); err != nil {
+		return
+	}
+
+	var (
+		replicas []schemas.Replica
+		locks    []schemas.TaskLock
+	)
+
+	if replicas, err = c.Replicas(ctx); err != nil {
+		return
+	}
+
+	if locks, err = c.Store.TaskLocks(ctx); err != nil {
+		return
+	}
+
+	running := make(map[schemas.TaskType]bool)
+	for _, r := range append(replicas, c.Replica()) {
+		for _, tt := range r.RunningTasks {
+			running[tt] = true
+		}
+	}
+
+	for _, l := range locks {
+		if running[l.TaskType] {
+			continue
+		}
+
+		if err = c.Store.DelTaskLock(ctx, l.TaskType, l.UniqueID); err != nil {
+			return
+		}
+
+		released++
+	}
+
+	log.WithContext(ctx).
+		WithField("released_task_locks", released).
+		Info("task queue purged")
+
+	return
+}
+
+// ReleaseStaleTaskLocks removes the task locks held by processes which are no
+// longer running, as told by their Liveness. It returns the number of released locks.
+func (c *Controller) ReleaseStaleTaskLocks(ctx context.Context) (released int, err error) {
+	var (
+		replicas []schemas.Replica
+		locks    []schemas.TaskLock
+	)
+
+	if replicas, err = c.Replicas(ctx); err != nil {
+		return
+	}
+
+	if locks, err = c.Store.TaskLocks(ctx); err != nil {
+		return
+	}
+
+	liveness := c.Liveness(replicas)
+
+	for _, l := range locks {
+		var isAlive bool
+
+		if isAlive, err = liveness.Alive(ctx, l.Owner); err != nil {
+			return
+		}
+
+		if isAlive {
+			continue
+		}
+
+		if err = c.Store.DelTaskLock(ctx, l.TaskType, l.UniqueID); err != nil {
+			return
+		}
+
+		released++
+	}
+
+	log.WithContext(ctx).
+		WithField("released_task_locks", released).
+		Info("stale task locks released")
+
+	return
+}
+
+// RunTasks executes the tasks declared by the enabled sources once, synchronously and within
+// this process, regardless of their schedule. The other tasks, such as the garbage collection
+// or the relay of the webhook deliveries, are left aside. It returns the errors of the failed tasks.
+func (c *Controller) RunTasks(ctx context.Context) error {
+	var taskTypes []schemas.TaskType
+
+	for _, s := range c.Sources {
+		for tt := range s.Schedule() {
+			taskTypes = append(taskTypes, tt)
+		}
+	}
+
+	sort.Slice(taskTypes, func(i, j int) bool { return taskTypes[i] < taskTypes[j] })
+
+	var errs []error
+
+	for _, tt := range taskTypes {
+		task := c.TaskController.TaskMap.Get(string(tt))
+
+		if err := task.HandleJob(ctx, newTaskJob(task, "")); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", tt, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,190 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/config"
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/schemas"
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/store"
+)
+
+// runTasksSource declares tasks whose executions get recorded.
+type runTasksSource struct {
+	executed *[]schemas.TaskType
+}
+
+func (s runTasksSource) Name() string { return "test" }
+
+func (s runTasksSource) Tasks() map[schemas.TaskType]interface{} {
+	return map[schemas.TaskType]interface{}{
+		"foo": func() error {
+			*s.executed = append(*s.executed, "foo")
+
+			return nil
+		},
+		"bar": func() error {
+			*s.executed = append(*s.executed, "bar")
+
+			return errors.New("boom")
+		},
+	}
+}
+
+func (s runTasksSource) Schedule() map[schemas.TaskType]config.SchedulerConfig {
+	return map[schemas.TaskType]config.SchedulerConfig{
+		"foo": {},
+		"bar": {},
+	}
+}
+
+func (s runTasksSource) Collectors() RegistryCollectors { return RegistryCollectors{} }
+
+func (s runTasksSource) Health(_ context.Context) error { return nil }
+
+func TestRunTasks(t *testing.T) {
+	ctx := context.Background()
+
+	c, err := New(ctx, config.New(), "test")
+	require.NoError(t, err)
+
+	var executed []schemas.TaskType
+
+	s := runTasksSource{executed: &executed}
+	for tt, h := range s.Tasks() {
+		c.RegisterTasks(tt, h)
+	}
+
+	c.Sources = append(c.Sources, s)
+
+	// The tasks which are not declared by the sources are left aside
+	c.RegisterTasks(schemas.TaskTypeGarbageCollectMetrics, func() error {
+		executed = append(executed, schemas.TaskTypeGarbageCollectMetrics)
+
+		return nil
+	})
+
+	err = c.RunTasks(ctx)
+	assert.EqualError(t, err, "bar: boom")
+	assert.Equal(t, []schemas.TaskType{"bar", "foo"}, executed)
+
+	// The executions are part of the exposition
+	var b bytes.Buffer
+
+	require.NoError(t, c.WriteMetrics(ctx, &b, false))
+	assert.Contains(t, b.String(), `mre_task_duration_seconds_count{outcome="error",task_type="bar"} 1`)
+	assert.Contains(t, b.String(), `mre_task_duration_seconds_count{outcome="success",task_type="foo"} 1`)
+	assert.NotContains(t, b.String(), "# EOF")
+
+	b.Reset()
+	require.NoError(t, c.WriteMetrics(ctx, &b, true))
+	assert.True(t, strings.HasSuffix(b.String(), "# EOF\n"))
+}
+
+func TestReleaseStaleTaskLocks(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mr := miniredis.RunT(t)
+
+	cfg := config.New()
+	cfg.Redis.URL = "redis://" + mr.Addr()
+
+	c, err := New(ctx, cfg, "test")
+	require.NoError(t, err)
+
+	c.ScheduleRedisSetKeepalive(ctx)
+
+	s := c.Store.(*store.Redis)
+
+	// Processes running older versions only set their keepalive
+	_, err = s.SetKeepalive(ctx, "legacy", time.Minute)
+	require.NoError(t, err)
+
+	for _, owner := range []string{c.UUID.String(), "legacy", "dead"} {
+		_, err = s.QueueTask(ctx, schemas.TaskType(owner), "_", owner)
+		require.NoError(t, err)
+	}
+
+	released, err := c.ReleaseStaleTaskLocks(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, released)
+
+	assert.True(t, mr.Exists("task:"+c.UUID.String()+":_"))
+	assert.True(t, mr.Exists("task:legacy:_"))
+	assert.False(t, mr.Exists("task:dead:_"))
+}
+
+func TestPurgeTaskQueue(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mr := miniredis.RunT(t)
+
+	cfg := config.New()
+	cfg.Redis.URL = "redis://" + mr.Addr()
+
+	c, err := New(ctx, cfg, "test")
+	require.NoError(t, err)
+
+	other, err := New(ctx, cfg, "test")
+	require.NoError(t, err)
+
+	other.ScheduleRedisSetKeepalive(ctx)
+
+	// Another replica is executing a task whilst a second one is waiting in the queue
+	started, release := make(chan struct{}), make(chan struct{})
+
+	other.RegisterTasks("running", func(ctx context.Context) error {
+		defer other.UnqueueTask(ctx, "running", "_")
+
+		close(started)
+		<-release
+
+		return nil
+	})
+
+	for _, tt := range []schemas.TaskType{"running", "queued"} {
+		_, err = c.Store.QueueTask(ctx, tt, "_", other.UUID.String())
+		require.NoError(t, err)
+	}
+
+	task := other.TaskController.TaskMap.Get("running")
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		_ = task.HandleJob(ctx, newTaskJob(task, ""))
+	}()
+
+	<-started
+
+	released, err := c.PurgeTaskQueue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, released)
+
+	assert.True(t, mr.Exists("task:running:_"))
+	assert.False(t, mr.Exists("task:queued:_"))
+
+	// The lock of the running task gets released once completed
+	close(release)
+	<-done
+
+	assert.False(t, mr.Exists("task:running:_"))
+
+	replicas, err := c.Replicas(ctx)
+	require.NoError(t, err)
+
+	for _, r := range replicas {
+		assert.Empty(t, r.RunningTasks)
+	}
+}
@@ -2,9 +2,15 @@ package controller
 
 import (
 	"context"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
 	log "github.com/sirupsen/logrus"
@@ -22,8 +28,12 @@ import (
 	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/store"
 )
 
+// tracesFlushGracePeriod is the minimum time given to the traces to be flushed upon shutdown.
+const tracesFlushGracePeriod = 5 * time.Second
+
 type Controller struct {
-	Config config.Config
+	// cfg holds the current config, it can be swapped at runtime using Reload
+	cfg *atomic.Pointer[config.Config]
 
 	// UUID is used to identify this controller/process amongst others when
 	// the exporter is running in cluster mode, leveraging Redis.
@@ -32,16 +42,57 @@ type Controller struct {
 	TaskController TaskController
 	Store          store.Store
 
-	Collectors RegistryCollectors
+	Sources []Source
+
+	ConfigReloadTelemetry ConfigReloadTelemetry
+	WebhookTelemetry      WebhookTelemetry
+	WebhookRelayTelemetry WebhookRelayTelemetry
+
+	// version, hostname and startedAt describe the process to the other replicas
+	version   string
+	hostname  string
+	startedAt time.Time
+	// leader tells whether the process is the leader amongst the replicas
+	leader *atomic.Bool
+
+	// schedulers keeps track of the running scheduling goroutines
+	schedulers          *sync.WaitGroup
+	schedulerHeartbeats *schedulerHeartbeats
+	tickers             *taskTickers
+	webhookDebouncer    *webhookDebouncer
+	tracerProvider      *sdktrace.TracerProvider
+
+	// collectorFactories build the collectors of each export, metricNames holds the
+	// names of the metrics they export
+	collectorFactories []func() RegistryCollectors
+	metricNames        map[schemas.MetricKind]string
+
+	// healthCheckCollectors expose the status of the health checks
+	healthCheckCollectors []prometheus.Collector
 }
 
 // New creates a new controller.
 func New(ctx context.Context, cfg config.Config, version string) (c Controller, err error) {
-	c.Config = cfg
+	c.cfg = &atomic.Pointer[config.Config]{}
+	c.cfg.Store(&cfg)
 	c.UUID = uuid.New()
-	c.Collectors = make(RegistryCollectors)
+	c.metricNames = make(map[schemas.MetricKind]string)
+	c.schedulers = &sync.WaitGroup{}
+	c.schedulerHeartbeats = newSchedulerHeartbeats()
+	c.tickers = newTaskTickers()
+	c.ConfigReloadTelemetry = NewConfigReloadTelemetry()
+	c.WebhookTelemetry = NewWebhookTelemetry()
+	c.WebhookRelayTelemetry = NewWebhookRelayTelemetry()
+	c.webhookDebouncer = newWebhookDebouncer()
+	c.version = version
+	c.startedAt = time.Now()
+	c.leader = &atomic.Bool{}
+
+	if c.hostname, err = os.Hostname(); err != nil {
+		return
+	}
 
-	if err = configureTracing(ctx, &cfg.OpenTelemetry); err != nil {
+	if c.tracerProvider, err = configureTracing(ctx, &cfg.OpenTelemetry); err != nil {
 		return
 	}
 
@@ -52,15 +103,23 @@ func New(ctx context.Context, cfg config.Config, version string) (c Controller,
 	c.TaskController = NewTaskController(ctx, c.Redis, cfg)
 	c.Store = store.New(ctx, c.Redis)
 
+	// Without Redis, the process runs on its own
+	c.leader.Store(c.Redis == nil)
+
 	return
 }
 
+// Config returns the current config of the controller.
+func (c *Controller) Config() config.Config {
+	return *c.cfg.Load()
+}
+
 // configureTracing setup OTEL endpoint.
-func configureTracing(ctx context.Context, cfg *config.OpenTelemetry) error {
+func configureTracing(ctx context.Context, cfg *config.OpenTelemetry) (*sdktrace.TracerProvider, error) {
 	if len(cfg.GRPCEndpoint) == 0 {
 		log.Debug("opentelemetry.grpc_endpoint is not configured, skipping open telemetry support")
 
-		return nil
+		return nil, nil
 	}
 
 	log.WithFields(
@@ -77,7 +136,7 @@ func configureTracing(ctx context.Context, cfg *config.OpenTelemetry) error {
 
 	traceExp, err := otlptrace.New(ctx, traceClient)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	res, err := resource.New(
@@ -91,7 +150,7 @@ func configureTracing(ctx context.Context, cfg *config.OpenTelemetry) error {
 		),
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	bsp := sdktrace.NewBatchSpanProcessor(traceExp)
@@ -103,12 +162,12 @@ func configureTracing(ctx context.Context, cfg *config.OpenTelemetry) error {
 
 	otel.SetTracerProvider(tracerProvider)
 
-	return nil
+	return tracerProvider, nil
 }
 
 // configureRedis is used in distributed mode, in that case the jobs/task backend is redis instead of in-memory.
 func (c *Controller) configureRedis(ctx context.Context, url string) (err error) {
-	ctx, span := otel.Tracer(c.Config.OpenTelemetry.ServiceNameKey).Start(ctx, "controller:configureRedis")
+	ctx, span := otel.Tracer(c.Config().OpenTelemetry.ServiceNameKey).Start(ctx, "controller:configureRedis")
 	defer span.End()
 
 	if len(url) <= 0 {
@@ -144,21 +203,54 @@ func (c *Controller) configureRedis(ctx context.Context, url string) (err error)
 func (c *Controller) RegisterTasks(n schemas.TaskType, h interface{}) {
 	_, _ = c.TaskController.TaskMap.Register(
 		string(n), &taskq.TaskConfig{
-			Handler:    h,
+			Handler: &instrumentedHandler{
+				tt:        n,
+				handler:   taskq.NewHandler(h),
+				telemetry: c.TaskController.Telemetry,
+				store:     c.Store,
+
+				publishRunningTasks: c.publishRunningTasks,
+			},
 			RetryLimit: 1,
 		},
 	)
 }
 
-// RegisterCollector is used to add collectors to the registry
-func (c *Controller) RegisterCollector(ctx context.Context, collectors RegistryCollectors) {
-	for kind, collector := range collectors {
-		if _, ok := c.Collectors[kind]; ok {
+// RegisterCollector is used to add collectors to the registry. The factory is called
+// for each export, so that concurrent exports do not share their values.
+func (c *Controller) RegisterCollector(ctx context.Context, factory func() RegistryCollectors) {
+	for kind, collector := range factory() {
+		if _, ok := c.metricNames[kind]; ok {
 			log.WithContext(ctx).Warn("Duplicated Collector key - skipping")
 		}
 
-		c.Collectors[kind] = collector
+		c.metricNames[kind] = collector.Name
+	}
+
+	c.collectorFactories = append(c.collectorFactories, factory)
+}
+
+// MetricName returns the name of the metric exported for the kind, or the kind
+// itself if no collector is registered for it.
+func (c *Controller) MetricName(kind schemas.MetricKind) string {
+	if name, ok := c.metricNames[kind]; ok {
+		return name
+	}
+
+	return strconv.Itoa(int(kind))
+}
+
+// newCollectors returns new instances of the registered collectors.
+func (c *Controller) newCollectors() RegistryCollectors {
+	collectors := make(RegistryCollectors)
+
+	for _, f := range c.collectorFactories {
+		for kind, collector := range f() {
+			collectors[kind] = collector
+		}
 	}
+
+	return collectors
 }
 
 func (c *Controller) UnqueueTask(ctx context.Context, tt schemas.TaskType, uniqueID string) {
@@ -174,3 +266,93 @@ func (c *Controller) UnqueueTask(ctx context.Context, tt schemas.TaskType, uniqu
 			Warn("unqueuing task")
 	}
 }
+
+// Shutdown gracefully stops the controller. It is expected to be called once the context
+// given to the schedulers has been cancelled, the provided one bounds its duration.
+func (c *Controller) Shutdown(ctx context.Context) (err error) {
+	// Wait for the schedulers to stop in order not to queue any new task
+	c.webhookDebouncer.stop()
+
+	schedulersStopped := make(chan struct{})
+
+	go func() {
+		c.schedulers.Wait()
+		close(schedulersStopped)
+	}()
+
+	select {
+	case <-schedulersStopped:
+		log.Debug("schedulers stopped")
+	case <-ctx.Done():
+		log.WithContext(ctx).Warn("timed out waiting for the schedulers to stop")
+	}
+
+	// Drain the queues, tasks which cannot be completed in time are released
+	timeout := time.Duration(c.Config().Scheduler.ShutdownTimeoutSeconds) * time.Second
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+
+	c.TaskController.Factory.Range(func(q taskq.Queue) bool {
+		if err := q.CloseTimeout(timeout); err != nil {
+			log.WithContext(ctx).
+				WithField("queue", q.Name()).
+				WithError(err).
+				Warn("draining the task queue")
+		}
+
+		return true
+	})
+
+	log.Debug("task queues closed")
+
+	if c.Redis != nil {
+		s := c.Store.(*store.Redis)
+
+		// We use a new context as the remaining steps should not be skipped if we ran out of time
+		cleanupCtx := context.Background()
+
+		if err := s.DelKeepalive(cleanupCtx, c.UUID.String()); err != nil {
+			log.WithContext(ctx).
+				WithError(err).
+				Warn("removing keepalive")
+		}
+
+		c.unpublishReplica(cleanupCtx)
+
+		released, err := s.ReleaseTasks(cleanupCtx, c.UUID.String())
+		if err != nil {
+			log.WithContext(ctx).
+				WithError(err).
+				Warn("releasing task locks")
+		}
+
+		log.WithField("count", released).Debug("task locks released")
+
+		if err = c.Redis.Close(); err != nil {
+			return errors.Wrap(err, "closing redis connection")
+		}
+
+		log.Debug("redis connection closed")
+	}
+
+	if c.tracerProvider != nil {
+		// The traces are flushed within the time left, or a short grace period if we ran out of it,
+		// the collector being unreachable must not hold the shutdown
+		flushTimeout := tracesFlushGracePeriod
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) > flushTimeout {
+			flushTimeout = time.Until(deadline)
+		}
+
+		flushCtx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+		defer cancel()
+
+		if err = c.tracerProvider.Shutdown(flushCtx); err != nil {
+			return errors.Wrap(err, "flushing traces")
+		}
+
+		log.Debug("tracer provider flushed")
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/config"
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/store"
+)
+
+func TestShutdown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	c, err := New(ctx, config.New(), "test")
+	require.NoError(t, err)
+
+	var completed atomic.Int32
+
+	started := make(chan struct{})
+
+	c.RegisterTasks("slow", func(ctx context.Context) error {
+		defer c.UnqueueTask(ctx, "slow", "_")
+
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		completed.Add(1)
+
+		return nil
+	})
+	c.ScheduleTaskWithTicker(ctx, "slow", 60)
+	assert.Empty(t, c.ScheduleTask(ctx, "slow", "_"))
+
+	// The shutdown starts whilst the task is being executed
+	<-started
+
+	cancel()
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer shutdownCancel()
+
+	require.NoError(t, c.Shutdown(shutdownCtx))
+
+	// The schedulers stopped and the queued task got completed
+	assert.NoError(t, c.schedulerHeartbeats.Check())
+	assert.Empty(t, c.schedulerHeartbeats.beats)
+	assert.Equal(t, int32(1), completed.Load())
+
+	count, err := c.Store.CurrentlyQueuedTasksCount(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	c, err := New(context.Background(), config.New(), "test")
+	require.NoError(t, err)
+
+	started := make(chan struct{})
+
+	c.RegisterTasks("stuck", func(ctx context.Context) error {
+		close(started)
+		time.Sleep(2 * time.Second)
+
+		return nil
+	})
+	assert.Empty(t, c.ScheduleTask(context.Background(), "stuck", "_"))
+
+	<-started
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer shutdownCancel()
+
+	// Tasks which cannot be completed in time do not hold the shutdown
+	start := time.Now()
+
+	_ = c.Shutdown(shutdownCtx)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestShutdownReleasesTaskLocks(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	mr := miniredis.RunT(t)
+
+	cfg := config.New()
+	cfg.Redis.URL = "redis://" + mr.Addr()
+
+	c, err := New(ctx, cfg, "test")
+	require.NoError(t, err)
+
+	s := c.Store.(*store.Redis)
+
+	c.ScheduleRedisSetKeepalive(ctx)
+
+	// The process is published before the first tick of the keepalive
+	assert.True(t, mr.Exists("keepalive:"+c.UUID.String()))
+	assert.True(t, mr.Exists("replica:"+c.UUID.String()))
+
+	// Locks held by this process and by another one
+	_, err = s.QueueTask(ctx, "foo", "_", c.UUID.String())
+	require.NoError(t, err)
+	_, err = s.QueueTask(ctx, "bar", "_", c.UUID.String())
+	require.NoError(t, err)
+	_, err = s.QueueTask(ctx, "baz", "_", "other")
+	require.NoError(t, err)
+
+	cancel()
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer shutdownCancel()
+
+	require.NoError(t, c.Shutdown(shutdownCtx))
+
+	// Only the locks of this process got released, along with its keepalive and replica
+	assert.False(t, mr.Exists("task:foo:_"))
+	assert.False(t, mr.Exists("task:bar:_"))
+	assert.True(t, mr.Exists("task:baz:_"))
+	assert.False(t, mr.Exists("keepalive:"+c.UUID.String()))
+	assert.False(t, mr.Exists("replica:"+c.UUID.String()))
+
+	// The connection got closed
+	assert.ErrorContains(t, c.Redis.Ping(context.Background()).Err(), "closed")
+}
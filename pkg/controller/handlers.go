@@ -2,30 +2,71 @@ package controller
 
 import (
 	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/heptiolabs/healthcheck"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/common/expfmt"
 	log "github.com/sirupsen/logrus"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/otel/trace"
+
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/schemas"
+)
+
+// List of the statuses returned by the admin task endpoint, on top of the task skipped reasons.
+const (
+	AdminTaskStatusScheduled = "scheduled"
+	AdminTaskStatusTimeout   = "timeout"
+)
+
+const (
+	// adminTaskDefaultWaitTimeout is how long to wait for the task outcome unless specified otherwise.
+	adminTaskDefaultWaitTimeout = 30 * time.Second
+
+	// adminTaskPollInterval is how often the store is checked for the task outcome.
+	adminTaskPollInterval = 250 * time.Millisecond
 )
 
+// AdminTaskResponse is returned by the admin task endpoint.
+type AdminTaskResponse struct {
+	TaskType schemas.TaskType `json:"task_type"`
+	Status   string           `json:"status"`
+	Error    string           `json:"error,omitempty"`
+}
+
 // HealthCheckHandler ..
 func (c *Controller) HealthCheckHandler(ctx context.Context) (h healthcheck.Handler) {
 	h = healthcheck.NewHandler()
 
-	return
-}
+	for name, check := range c.readinessChecks(ctx) {
+		h.AddReadinessCheck(name, check)
+		c.healthCheckCollectors = append(c.healthCheckCollectors, NewInternalCollectorHealthCheckStatus(name, check))
+	}
 
-// MetricsHandler ..
-func (c *Controller) MetricsHandler(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-	span := trace.SpanFromContext(ctx)
+	for name, check := range c.livenessChecks() {
+		h.AddLivenessCheck(name, check)
+		c.healthCheckCollectors = append(c.healthCheckCollectors, NewInternalCollectorHealthCheckStatus(name, check))
+	}
 
-	defer span.End()
+	return
+}
 
-	registry := NewRegistry(ctx, c.Collectors)
+// registry returns a registry exporting the current metrics.
+func (c *Controller) registry(ctx context.Context) *Registry {
+	registry := NewRegistry(ctx, c.newCollectors())
+	registry.RegisterTaskTelemetry(c.TaskController.Telemetry)
+	registry.RegisterHealthChecks(c.healthCheckCollectors)
+	registry.RegisterConfigReloadTelemetry(c.ConfigReloadTelemetry)
+	registry.RegisterWebhookTelemetry(c.WebhookTelemetry)
+	registry.RegisterWebhookRelayTelemetry(c.WebhookRelayTelemetry)
 
 	metrics, err := c.Store.Metrics(ctx)
 	if err != nil {
@@ -45,18 +86,57 @@ func (c *Controller) MetricsHandler(w http.ResponseWriter, r *http.Request) {
 
 	registry.ExportMetrics(metrics)
 
+	return registry
+}
+
+// WriteMetrics writes the exposition of the current metrics, in the OpenMetrics
+// format if requested or the Prometheus text format otherwise.
+func (c *Controller) WriteMetrics(ctx context.Context, w io.Writer, openMetrics bool) error {
+	families, err := c.registry(ctx).Gather()
+	if err != nil {
+		return err
+	}
+
+	format := expfmt.FmtText
+	if openMetrics {
+		format = expfmt.FmtOpenMetrics_1_0_0
+	}
+
+	encoder := expfmt.NewEncoder(w, format)
+	for _, family := range families {
+		if err = encoder.Encode(family); err != nil {
+			return err
+		}
+	}
+
+	if closer, ok := encoder.(expfmt.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+// MetricsHandler ..
+func (c *Controller) MetricsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := trace.SpanFromContext(ctx)
+
+	defer span.End()
+
+	registry := c.registry(ctx)
+
 	otelhttp.NewHandler(
 		promhttp.HandlerFor(
 			registry, promhttp.HandlerOpts{
 				Registry:          registry,
-				EnableOpenMetrics: c.Config.Server.Metrics.EnableOpenmetricsEncoding,
+				EnableOpenMetrics: c.Config().Server.Metrics.EnableOpenmetricsEncoding,
 			},
 		),
 		"/metrics",
 	).ServeHTTP(w, r)
 }
 
-// WebhookHandler ..
+// WebhookHandler authenticates the webhook requests and schedules the refresh of the sources data.
 func (c *Controller) WebhookHandler(w http.ResponseWriter, r *http.Request) {
 	span := trace.SpanFromContext(r.Context())
 	defer span.End()
@@ -74,4 +154,201 @@ func (c *Controller) WebhookHandler(w http.ResponseWriter, r *http.Request) {
 		)
 
 	logger.Debug("webhook request")
+
+	if r.Method != http.MethodPost {
+		c.WebhookTelemetry.IncRequests(WebhookSourceUnknown, "", WebhookResultInvalid)
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, webhookMaxPayloadBytes))
+	if err != nil {
+		c.WebhookTelemetry.IncRequests(WebhookSourceUnknown, "", WebhookResultInvalid)
+		logger.WithError(err).Warn("reading webhook request body")
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+
+		return
+	}
+
+	source, event, ok := verifyWebhookRequest(r, body, c.Config().Server.Webhook.SecretToken)
+	if !ok {
+		c.WebhookTelemetry.IncRequests(source, "", WebhookResultUnauthorized)
+		logger.WithField("source", source).Warn("unauthorized webhook request")
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+
+		return
+	}
+
+	c.WebhookTelemetry.IncRequests(source, event, WebhookResultAccepted)
+	logger.WithFields(
+		log.Fields{
+			"source": source,
+			"event":  event,
+		},
+	).Debug("webhook request accepted")
+
+	c.consumeWebhook(ctx, WebhookEvent{Source: source, Event: event, Body: body})
+	c.scheduleWebhookTasks(ctx)
+
+	if c.Config().Server.Webhook.Relay.Enabled {
+		c.relayWebhook(ctx, schemas.WebhookDelivery{
+			Source:     source,
+			Event:      event,
+			Repository: webhookRepository(body),
+			Header:     r.Header.Clone(),
+			Body:       body,
+			ReceivedAt: time.Now(),
+		})
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// AdminTaskHandler enqueues the task named after the last segment of the path, eg: POST /admin/tasks/<task_type>.
+// When the `wait` query parameter is set, it returns the outcome of the task execution once done, or after
+// `timeout` seconds. Tasks already queued are not scheduled twice, their outcome cannot be awaited.
+func (c *Controller) AdminTaskHandler(w http.ResponseWriter, r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	defer span.End()
+
+	if !c.authorizeAdminRequest(r) {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	tt := schemas.TaskType(strings.TrimPrefix(r.URL.Path, "/admin/tasks/"))
+	if c.TaskController.TaskMap.Get(string(tt)) == nil {
+		http.Error(w, "unknown task type", http.StatusNotFound)
+
+		return
+	}
+
+	wait, _ := strconv.ParseBool(r.URL.Query().Get("wait"))
+
+	timeout := adminTaskDefaultWaitTimeout
+	if v := r.URL.Query().Get("timeout"); v != "" {
+		seconds, err := strconv.Atoi(v)
+		if err != nil || seconds <= 0 {
+			http.Error(w, "invalid timeout", http.StatusBadRequest)
+
+			return
+		}
+
+		timeout = time.Duration(seconds) * time.Second
+	}
+
+	// The task must outlive the request unless we wait for its outcome
+	ctx := trace.ContextWithSpan(context.Background(), span)
+
+	logger := log.
+		WithContext(ctx).
+		WithFields(
+			log.Fields{
+				"ip-address": r.RemoteAddr,
+				"task_type":  tt,
+				"wait":       wait,
+			},
+		)
+
+	logger.Info("admin task request")
+
+	resp := AdminTaskResponse{
+		TaskType: tt,
+		Status:   AdminTaskStatusScheduled,
+	}
+
+	// The job carries an execution ID so that we only wait for its own outcome,
+	// whichever process ends up executing it
+	var executionID string
+	if wait {
+		executionID = uuid.NewString()
+	}
+
+	if skipReason := c.scheduleTask(ctx, tt, "_", executionID); skipReason != "" {
+		resp.Status = skipReason
+
+		switch {
+		case skipReason != TaskSkippedReasonAlreadyQueued:
+			writeAdminTaskResponse(w, http.StatusServiceUnavailable, resp)
+		case wait:
+			// The outcome of a job queued by someone else cannot be awaited
+			writeAdminTaskResponse(w, http.StatusConflict, resp)
+		default:
+			writeAdminTaskResponse(w, http.StatusAccepted, resp)
+		}
+
+		return
+	}
+
+	if !wait {
+		writeAdminTaskResponse(w, http.StatusAccepted, resp)
+
+		return
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	ticker := time.NewTicker(adminTaskPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			e, ok, err := c.Store.TaskExecution(ctx, executionID)
+			if err != nil {
+				logger.WithError(err).Warn("reading task execution outcome")
+
+				continue
+			}
+
+			if !ok {
+				continue
+			}
+
+			resp.Status = e.Outcome
+			resp.Error = e.Error
+			writeAdminTaskResponse(w, http.StatusOK, resp)
+
+			return
+		case <-timer.C:
+			resp.Status = AdminTaskStatusTimeout
+			writeAdminTaskResponse(w, http.StatusGatewayTimeout, resp)
+
+			return
+		case <-r.Context().Done():
+			logger.Debug("admin task request cancelled")
+
+			return
+		}
+	}
+}
+
+// authorizeAdminRequest checks the bearer token of the request against the configured one.
+func (c *Controller) authorizeAdminRequest(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(token), []byte(c.Config().Server.Admin.Token)) == 1
+}
+
+func writeAdminTaskResponse(w http.ResponseWriter, code int, resp AdminTaskResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.WithError(err).Warn("writing admin task response")
+	}
 }
@@ -0,0 +1,149 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/config"
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/schemas"
+)
+
+func newAdminTestController(t *testing.T) *Controller {
+	cfg := config.New()
+	cfg.Server.Admin.Enabled = true
+	cfg.Server.Admin.Token = "secret"
+
+	c, err := New(context.Background(), cfg, "test")
+	require.NoError(t, err)
+
+	return &c
+}
+
+func adminTaskRequest(t *testing.T, c *Controller, target, token string) (int, AdminTaskResponse) {
+	req := httptest.NewRequest(http.MethodPost, target, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	w := httptest.NewRecorder()
+	c.AdminTaskHandler(w, req)
+
+	var resp AdminTaskResponse
+	if w.Header().Get("Content-Type") == "application/json" {
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	}
+
+	return w.Code, resp
+}
+
+func TestAdminTaskHandlerUnauthorized(t *testing.T) {
+	c := newAdminTestController(t)
+	c.RegisterTasks("foo", func() error { return nil })
+
+	code, _ := adminTaskRequest(t, c, "/admin/tasks/foo", "")
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	code, _ = adminTaskRequest(t, c, "/admin/tasks/foo", "other")
+	assert.Equal(t, http.StatusUnauthorized, code)
+}
+
+func TestAdminTaskHandlerUnknownTaskType(t *testing.T) {
+	c := newAdminTestController(t)
+
+	code, _ := adminTaskRequest(t, c, "/admin/tasks/foo", "secret")
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+func TestAdminTaskHandlerAlreadyQueued(t *testing.T) {
+	c := newAdminTestController(t)
+	c.RegisterTasks("foo", func() error { return nil })
+
+	// Another process already queued the task
+	_, err := c.Store.QueueTask(context.Background(), "foo", "_", "other")
+	require.NoError(t, err)
+
+	code, resp := adminTaskRequest(t, c, "/admin/tasks/foo", "secret")
+	assert.Equal(t, http.StatusAccepted, code)
+	assert.Equal(t, TaskSkippedReasonAlreadyQueued, resp.Status)
+
+	// Its outcome is not ours to wait for
+	code, resp = adminTaskRequest(t, c, "/admin/tasks/foo?wait=true", "secret")
+	assert.Equal(t, http.StatusConflict, code)
+	assert.Equal(t, TaskSkippedReasonAlreadyQueued, resp.Status)
+}
+
+func TestAdminTaskHandlerWait(t *testing.T) {
+	c := newAdminTestController(t)
+
+	release := make(chan struct{})
+
+	c.RegisterTasks("foo", func(ctx context.Context) error {
+		defer c.UnqueueTask(ctx, "foo", "_")
+
+		<-release
+
+		return errors.New("boom")
+	})
+
+	type result struct {
+		code int
+		resp AdminTaskResponse
+	}
+
+	done := make(chan result, 1)
+
+	go func() {
+		code, resp := adminTaskRequest(t, c, "/admin/tasks/foo?wait=true", "secret")
+		done <- result{code, resp}
+	}()
+
+	// Executions of the same task type which were not queued by the request are not awaited
+	task := c.TaskController.TaskMap.Get("foo")
+	require.NoError(t, c.Store.SetTaskExecution(context.Background(), schemas.TaskExecution{ID: "other", TaskType: "foo", Outcome: TaskOutcomeSuccess}, time.Minute))
+
+	go func() { _ = task.HandleJob(context.Background(), newTaskJob(task, "")) }()
+
+	select {
+	case r := <-done:
+		t.Fatalf("the request returned before the execution of its task: %d %+v", r.code, r.resp)
+	case <-time.After(3 * adminTaskPollInterval):
+	}
+
+	close(release)
+
+	select {
+	case r := <-done:
+		assert.Equal(t, http.StatusOK, r.code)
+		assert.Equal(t, AdminTaskResponse{TaskType: "foo", Status: TaskOutcomeError, Error: "boom"}, r.resp)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the request did not return the outcome of its task")
+	}
+}
+
+func TestAdminTaskHandlerWaitTimeout(t *testing.T) {
+	c := newAdminTestController(t)
+
+	release := make(chan struct{})
+	defer close(release)
+
+	c.RegisterTasks("foo", func() error {
+		<-release
+
+		return nil
+	})
+
+	code, resp := adminTaskRequest(t, c, "/admin/tasks/foo?wait=true&timeout=1", "secret")
+	assert.Equal(t, http.StatusGatewayTimeout, code)
+	assert.Equal(t, AdminTaskStatusTimeout, resp.Status)
+
+	code, _ = adminTaskRequest(t, c, "/admin/tasks/foo?wait=true&timeout=0", "secret")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
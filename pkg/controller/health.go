@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/heptiolabs/healthcheck"
+)
+
+// Names of the health checks.
+const (
+	HealthCheckRedis        = "redis"
+	HealthCheckTaskFailures = "task_failures"
+	HealthCheckSchedulers   = "schedulers"
+)
+
+// schedulerHeartbeatMaxMissedTicks is the number of ticks a scheduler can miss before being considered dead.
+const schedulerHeartbeatMaxMissedTicks = 3
+
+type schedulerHeartbeat struct {
+	interval time.Duration
+	last     time.Time
+}
+
+// schedulerHeartbeats keeps track of the last tick of the scheduling goroutines.
+type schedulerHeartbeats struct {
+	mutex sync.RWMutex
+	beats map[string]schedulerHeartbeat
+}
+
+func newSchedulerHeartbeats() *schedulerHeartbeats {
+	return &schedulerHeartbeats{
+		beats: make(map[string]schedulerHeartbeat),
+	}
+}
+
+// Beat records that the scheduler is still running.
+func (h *schedulerHeartbeats) Beat(name string, interval time.Duration) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.beats[name] = schedulerHeartbeat{
+		interval: interval,
+		last:     time.Now(),
+	}
+}
+
+// Stop stops tracking the scheduler, it is expected to be called when it exits gracefully.
+func (h *schedulerHeartbeats) Stop(name string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	delete(h.beats, name)
+}
+
+// Check returns an error if any of the schedulers has not ticked for too long.
+func (h *schedulerHeartbeats) Check() error {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	for name, beat := range h.beats {
+		if since := time.Since(beat.last); since > schedulerHeartbeatMaxMissedTicks*beat.interval {
+			return fmt.Errorf("scheduler '%s' has not ticked for %s", name, since.Round(time.Second))
+		}
+	}
+
+	return nil
+}
+
+// readinessChecks returns the checks assessing whether the exporter is able to serve accurate metrics.
+func (c *Controller) readinessChecks(ctx context.Context) map[string]healthcheck.Check {
+	checks := map[string]healthcheck.Check{
+		// The queue consumer does not expose whether it is paused, the check reports the
+		// consecutive task failures from which it pauses itself, whatever their cause
+		HealthCheckTaskFailures: func() error {
+			threshold := c.TaskController.Queue.Options().PauseErrorsThreshold
+			if errs := c.TaskController.Telemetry.ConsecutiveErrors(); threshold > 0 && errs >= uint32(threshold) {
+				return fmt.Errorf("%d consecutive task executions failed", errs)
+			}
+
+			return nil
+		},
+	}
+
+	if c.Redis != nil {
+		checks[HealthCheckRedis] = func() error {
+			ctx, cancel := context.WithTimeout(ctx, time.Second)
+			defer cancel()
+
+			return c.Redis.Ping(ctx).Err()
+		}
+	}
+
+	for _, s := range c.Sources {
+		s := s
+		checks[s.Name()] = func() error {
+			return s.Health(ctx)
+		}
+	}
+
+	return checks
+}
+
+// livenessChecks returns the checks assessing whether the exporter should be restarted.
+func (c *Controller) livenessChecks() map[string]healthcheck.Check {
+	return map[string]healthcheck.Check{
+		HealthCheckSchedulers: c.schedulerHeartbeats.Check,
+	}
+}
+
+// HealthChecks returns the readiness and the liveness checks of the exporter, indexed by their names.
+func (c *Controller) HealthChecks(ctx context.Context) (readiness, liveness map[string]healthcheck.Check) {
+	return c.readinessChecks(ctx), c.livenessChecks()
+}
@@ -0,0 +1,150 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/config"
+)
+
+// stallScheduler pretends the scheduler last ticked the given number of intervals ago.
+func stallScheduler(t *testing.T, h *schedulerHeartbeats, name string, intervals int) {
+	// The schedulers beat for the first time once their goroutine is started
+	require.Eventually(t, func() bool {
+		h.mutex.RLock()
+		defer h.mutex.RUnlock()
+
+		_, ok := h.beats[name]
+
+		return ok
+	}, time.Second, time.Millisecond)
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	beat := h.beats[name]
+	beat.last = time.Now().Add(-time.Duration(intervals) * beat.interval)
+	h.beats[name] = beat
+}
+
+func TestSchedulerHeartbeats(t *testing.T) {
+	h := newSchedulerHeartbeats()
+	assert.NoError(t, h.Check())
+
+	h.Beat("foo", time.Second)
+	h.Beat("bar", time.Minute)
+	assert.NoError(t, h.Check())
+
+	// Missing a few ticks is tolerated
+	stallScheduler(t, h, "foo", schedulerHeartbeatMaxMissedTicks-1)
+	assert.NoError(t, h.Check())
+
+	stallScheduler(t, h, "foo", schedulerHeartbeatMaxMissedTicks+1)
+	assert.ErrorContains(t, h.Check(), "scheduler 'foo' has not ticked for 4s")
+
+	// Ticking again makes it alive again
+	h.Beat("foo", time.Second)
+	assert.NoError(t, h.Check())
+
+	// Stopped schedulers are not tracked anymore
+	stallScheduler(t, h, "bar", schedulerHeartbeatMaxMissedTicks+1)
+	assert.Error(t, h.Check())
+
+	h.Stop("bar")
+	assert.NoError(t, h.Check())
+}
+
+func TestReadinessChecks(t *testing.T) {
+	ctx := context.Background()
+
+	c, err := New(ctx, config.New(), "test")
+	require.NoError(t, err)
+
+	checks := c.readinessChecks(ctx)
+	assert.Contains(t, checks, HealthCheckTaskFailures)
+	assert.NotContains(t, checks, HealthCheckRedis)
+
+	fail := true
+
+	c.RegisterTasks("foo", func() error {
+		if fail {
+			return errors.New("boom")
+		}
+
+		return nil
+	})
+	task := c.TaskController.TaskMap.Get("foo")
+
+	threshold := c.TaskController.Queue.Options().PauseErrorsThreshold
+	for i := 0; i < threshold; i++ {
+		assert.NoError(t, checks[HealthCheckTaskFailures]())
+		_ = task.HandleJob(ctx, newTaskJob(task, ""))
+	}
+
+	assert.EqualError(t, checks[HealthCheckTaskFailures](), "3 consecutive task executions failed")
+
+	// A single success makes it ready again
+	fail = false
+	_ = task.HandleJob(ctx, newTaskJob(task, ""))
+	assert.NoError(t, checks[HealthCheckTaskFailures]())
+}
+
+func TestLivenessChecks(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c, err := New(ctx, config.New(), "test")
+	require.NoError(t, err)
+
+	c.RegisterTasks("foo", func() error { return nil })
+	c.ScheduleTaskWithTicker(ctx, "foo", 60)
+
+	checks := c.livenessChecks()
+	assert.NoError(t, checks[HealthCheckSchedulers]())
+
+	stallScheduler(t, c.schedulerHeartbeats, "foo", schedulerHeartbeatMaxMissedTicks+1)
+	assert.ErrorContains(t, checks[HealthCheckSchedulers](), "scheduler 'foo' has not ticked for 4m0s")
+
+	// Schedulers which exit gracefully are not considered dead
+	c.stopTaskTicker("foo")
+	assert.NoError(t, checks[HealthCheckSchedulers]())
+}
+
+func TestHealthCheckStatusMetric(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c, err := New(ctx, config.New(), "test")
+	require.NoError(t, err)
+
+	c.RegisterTasks("foo", func() error { return errors.New("boom") })
+	c.ScheduleTaskWithTicker(ctx, "foo", 60)
+	c.HealthCheckHandler(ctx)
+
+	exposition := func() string {
+		var b bytes.Buffer
+
+		require.NoError(t, c.WriteMetrics(ctx, &b, false))
+
+		return b.String()
+	}
+
+	assert.Contains(t, exposition(), `mre_health_check_status{check="task_failures"} 0`)
+	assert.Contains(t, exposition(), `mre_health_check_status{check="schedulers"} 0`)
+
+	task := c.TaskController.TaskMap.Get("foo")
+	for i := 0; i < c.TaskController.Queue.Options().PauseErrorsThreshold; i++ {
+		_ = task.HandleJob(ctx, newTaskJob(task, ""))
+	}
+
+	stallScheduler(t, c.schedulerHeartbeats, "foo", schedulerHeartbeatMaxMissedTicks+1)
+
+	assert.Contains(t, exposition(), `mre_health_check_status{check="task_failures"} 1`)
+	assert.Contains(t, exposition(), `mre_health_check_status{check="schedulers"} 1`)
+}
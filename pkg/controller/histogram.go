@@ -0,0 +1,163 @@
+package controller
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/schemas"
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/store"
+)
+
+// histogramBucketLabel holds the upper bound of the bucket a stored histogram metric refers to,
+// the sum of the observations is stored using histogramSumBucket instead.
+const (
+	histogramBucketLabel = "le"
+	histogramSumBucket   = "sum"
+)
+
+// StoreObserveHistogram records an observation of a histogram whose state is kept in the store,
+// each cumulative bucket and the sum of the observations being stored as a separate metric.
+func StoreObserveHistogram(
+	ctx context.Context,
+	s store.Store,
+	kind schemas.MetricKind,
+	labels prometheus.Labels,
+	buckets []float64,
+	value float64,
+) {
+	observation := func(bucket string, v float64) schemas.Metric {
+		l := prometheus.Labels{histogramBucketLabel: bucket}
+		for name, value := range labels {
+			l[name] = value
+		}
+
+		return schemas.Metric{Kind: kind, Labels: l, Value: v}
+	}
+
+	for _, b := range buckets {
+		if value <= b {
+			StoreIncMetric(ctx, s, observation(strconv.FormatFloat(b, 'g', -1, 64), 1))
+		}
+	}
+
+	StoreIncMetric(ctx, s, observation("+Inf", 1))
+	StoreIncMetric(ctx, s, observation(histogramSumBucket, value))
+}
+
+// HistogramVec exposes the histograms recorded using StoreObserveHistogram, it gets
+// populated from the store metrics by Registry.ExportMetrics.
+type HistogramVec struct {
+	desc       *prometheus.Desc
+	labelNames []string
+	buckets    []float64
+
+	mutex      sync.Mutex
+	histograms map[string]*storedHistogram
+}
+
+type storedHistogram struct {
+	labelValues []string
+	buckets     map[float64]uint64
+	count       uint64
+	sum         float64
+}
+
+// NewHistogramVec creates a new HistogramVec.
+func NewHistogramVec(opts prometheus.HistogramOpts, labelNames []string) *HistogramVec {
+	if opts.Buckets == nil {
+		opts.Buckets = prometheus.DefBuckets
+	}
+
+	return &HistogramVec{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(opts.Namespace, opts.Subsystem, opts.Name),
+			opts.Help,
+			labelNames,
+			opts.ConstLabels,
+		),
+		labelNames: labelNames,
+		buckets:    opts.Buckets,
+		histograms: make(map[string]*storedHistogram),
+	}
+}
+
+// Buckets returns the upper bounds of the buckets, to be used with StoreObserveHistogram.
+func (h *HistogramVec) Buckets() []float64 {
+	return h.buckets
+}
+
+// Set loads a stored histogram metric.
+func (h *HistogramVec) Set(labels prometheus.Labels, value float64) {
+	labelValues := make([]string, len(h.labelNames))
+	for i, name := range h.labelNames {
+		labelValues[i] = labels[name]
+	}
+
+	key := strings.Join(labelValues, "\x00")
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	sh, ok := h.histograms[key]
+	if !ok {
+		sh = &storedHistogram{
+			labelValues: labelValues,
+			buckets:     make(map[float64]uint64, len(h.buckets)),
+		}
+
+		// Buckets without any observation are not stored
+		for _, b := range h.buckets {
+			sh.buckets[b] = 0
+		}
+
+		h.histograms[key] = sh
+	}
+
+	switch bucket := labels[histogramBucketLabel]; bucket {
+	case histogramSumBucket:
+		sh.sum = value
+	case "+Inf":
+		sh.count = uint64(value)
+	default:
+		if upperBound, err := strconv.ParseFloat(bucket, 64); err == nil && !math.IsInf(upperBound, 0) {
+			sh.buckets[upperBound] = uint64(value)
+		}
+	}
+}
+
+// Reset deletes all the histograms.
+func (h *HistogramVec) Reset() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.histograms = make(map[string]*storedHistogram)
+}
+
+// Describe implements prometheus.Collector.
+func (h *HistogramVec) Describe(ch chan<- *prometheus.Desc) {
+	ch <- h.desc
+}
+
+// Collect implements prometheus.Collector.
+func (h *HistogramVec) Collect(ch chan<- prometheus.Metric) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	keys := make([]string, 0, len(h.histograms))
+	for k := range h.histograms {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		sh := h.histograms[k]
+		ch <- prometheus.MustNewConstHistogram(h.desc, sh.count, sh.sum, sh.buckets, sh.labelValues...)
+	}
+}
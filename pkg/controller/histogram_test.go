@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/schemas"
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/store"
+)
+
+func TestStoredHistogramVec(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewLocalStore()
+
+	h := NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "test_seconds",
+			Help:    "Test histogram",
+			Buckets: []float64{1, 10},
+		},
+		[]string{"foo"},
+	)
+
+	for _, v := range []float64{0.5, 5, 50} {
+		StoreObserveHistogram(ctx, s, 1, prometheus.Labels{"foo": "bar"}, h.Buckets(), v)
+	}
+
+	metrics, err := s.Metrics(ctx)
+	require.NoError(t, err)
+
+	r := NewRegistry(ctx, RegistryCollectors{schemas.MetricKind(1): {Collector: h, Name: "test_seconds"}})
+
+	// Exporting several times must not alter the values
+	r.ExportMetrics(metrics)
+	r.ExportMetrics(metrics)
+
+	assert.NoError(t, testutil.CollectAndCompare(h, strings.NewReader(`
+# HELP test_seconds Test histogram
+# TYPE test_seconds histogram
+test_seconds_bucket{foo="bar",le="1"} 1
+test_seconds_bucket{foo="bar",le="10"} 2
+test_seconds_bucket{foo="bar",le="+Inf"} 3
+test_seconds_sum{foo="bar"} 55.5
+test_seconds_count{foo="bar"} 3
+`)))
+}
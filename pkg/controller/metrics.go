@@ -20,13 +20,22 @@ type Registry struct {
 		CurrentlyQueuedTasksCount prometheus.Collector
 		ExecutedTasksCount        prometheus.Collector
 		MetricsCount              prometheus.Collector
+		BufferedWebhookDeliveries prometheus.Collector
 	}
 
 	Collectors RegistryCollectors
 }
 
 // RegistryCollectors ..
-type RegistryCollectors map[schemas.MetricKind]prometheus.Collector
+type RegistryCollectors map[schemas.MetricKind]RegistryCollector
+
+// RegistryCollector is the collector of a kind of metric, along with the name of
+// the exported metric as it cannot be retrieved from the collector itself.
+type RegistryCollector struct {
+	prometheus.Collector
+
+	Name string
+}
 
 // NewRegistry initialize a new registry.
 func NewRegistry(ctx context.Context, collectors RegistryCollectors) *Registry {
@@ -50,10 +59,47 @@ func (r *Registry) RegisterInternalCollectors() {
 	r.InternalCollectors.CurrentlyQueuedTasksCount = NewInternalCollectorCurrentlyQueuedTasksCount()
 	r.InternalCollectors.ExecutedTasksCount = NewInternalCollectorExecutedTasksCount()
 	r.InternalCollectors.MetricsCount = NewInternalCollectorMetricsCount()
+	r.InternalCollectors.BufferedWebhookDeliveries = NewInternalCollectorWebhookRelayBufferedDeliveriesCount()
 
 	_ = r.Register(r.InternalCollectors.CurrentlyQueuedTasksCount)
 	_ = r.Register(r.InternalCollectors.ExecutedTasksCount)
 	_ = r.Register(r.InternalCollectors.MetricsCount)
+	_ = r.Register(r.InternalCollectors.BufferedWebhookDeliveries)
+}
+
+// RegisterTaskTelemetry declare the task execution collectors to the registry.
+func (r *Registry) RegisterTaskTelemetry(t TaskTelemetry) {
+	for _, c := range t.Collectors() {
+		_ = r.Register(c)
+	}
+}
+
+// RegisterHealthChecks declare the health checks status collectors to the registry.
+func (r *Registry) RegisterHealthChecks(collectors []prometheus.Collector) {
+	for _, c := range collectors {
+		_ = r.Register(c)
+	}
+}
+
+// RegisterConfigReloadTelemetry declare the config reload collectors to the registry.
+func (r *Registry) RegisterConfigReloadTelemetry(t ConfigReloadTelemetry) {
+	for _, c := range t.Collectors() {
+		_ = r.Register(c)
+	}
+}
+
+// RegisterWebhookTelemetry declare the webhook collectors to the registry.
+func (r *Registry) RegisterWebhookTelemetry(t WebhookTelemetry) {
+	for _, c := range t.Collectors() {
+		_ = r.Register(c)
+	}
+}
+
+// RegisterWebhookRelayTelemetry declare the webhook relay collectors to the registry.
+func (r *Registry) RegisterWebhookRelayTelemetry(t WebhookRelayTelemetry) {
+	for _, c := range t.Collectors() {
+		_ = r.Register(c)
+	}
 }
 
 // ExportInternalMetrics ..
@@ -65,6 +111,7 @@ func (r *Registry) ExportInternalMetrics(
 		currentlyQueuedTasks uint64
 		executedTasksCount   uint64
 		metricsCount         int64
+		bufferedDeliveries   int64
 	)
 
 	currentlyQueuedTasks, err = s.CurrentlyQueuedTasksCount(ctx)
@@ -82,9 +129,15 @@ func (r *Registry) ExportInternalMetrics(
 		return
 	}
 
+	bufferedDeliveries, err = s.BufferedWebhookDeliveriesCount(ctx)
+	if err != nil {
+		return
+	}
+
 	r.InternalCollectors.CurrentlyQueuedTasksCount.(*prometheus.GaugeVec).With(prometheus.Labels{}).Set(float64(currentlyQueuedTasks))
 	r.InternalCollectors.ExecutedTasksCount.(*prometheus.GaugeVec).With(prometheus.Labels{}).Set(float64(executedTasksCount))
 	r.InternalCollectors.MetricsCount.(*prometheus.GaugeVec).With(prometheus.Labels{}).Set(float64(metricsCount))
+	r.InternalCollectors.BufferedWebhookDeliveries.(*prometheus.GaugeVec).With(prometheus.Labels{}).Set(float64(bufferedDeliveries))
 
 	return
 }
@@ -102,7 +155,7 @@ func (r *Registry) RegisterCollectors() error {
 
 // GetCollector ..
 func (r *Registry) GetCollector(kind schemas.MetricKind) prometheus.Collector {
-	return r.Collectors[kind]
+	return r.Collectors[kind].Collector
 }
 
 // ExportMetrics ..
@@ -113,6 +166,8 @@ func (r *Registry) ExportMetrics(metrics schemas.Metrics) {
 			c.With(m.Labels).Set(m.Value)
 		case *prometheus.CounterVec:
 			c.With(m.Labels).Add(m.Value)
+		case *HistogramVec:
+			c.Set(m.Labels, m.Value)
 		default:
 			log.Errorf("unsupported collector type : %v", reflect.TypeOf(c))
 		}
@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/config"
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/schemas"
+)
+
+func TestConcurrentExports(t *testing.T) {
+	ctx := context.Background()
+
+	c, err := New(ctx, config.New(), "test")
+	require.NoError(t, err)
+
+	c.RegisterCollector(ctx, func() RegistryCollectors {
+		return RegistryCollectors{
+			100: NewCounterVecCollector(prometheus.CounterOpts{Name: "foo_total"}, []string{"repository"}),
+		}
+	})
+
+	const repositories = 50
+	for i := 0; i < repositories; i++ {
+		require.NoError(t, c.Store.SetMetric(ctx, schemas.Metric{
+			Kind:   100,
+			Labels: prometheus.Labels{"repository": fmt.Sprintf("foo/%d", i)},
+			Value:  2,
+		}))
+	}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			var b bytes.Buffer
+
+			assert.NoError(t, c.WriteMetrics(ctx, &b, false))
+
+			// Each export holds all the series, with their value counted once
+			for j := 0; j < repositories; j++ {
+				assert.Contains(t, b.String(), fmt.Sprintf(`foo_total{repository="foo/%d"} 2`+"\n", j))
+			}
+		}()
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,252 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/config"
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/schemas"
+)
+
+// List of the outcomes of the webhook deliveries forwarding.
+const (
+	WebhookRelayOutcomeForwarded   = "forwarded"
+	WebhookRelayOutcomeRejected    = "rejected"
+	WebhookRelayOutcomeUnavailable = "unavailable"
+)
+
+// hopByHopHeaders are meant for a single connection and must not be forwarded, as per RFC 7230.
+var hopByHopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// WebhookRelayTelemetry holds the collectors instrumenting the relaying of the webhooks to Mend Renovate.
+type WebhookRelayTelemetry struct {
+	Received        prometheus.Collector
+	ForwardDuration prometheus.Collector
+	ForwardFailures prometheus.Collector
+}
+
+// NewWebhookRelayTelemetry initializes and returns a new WebhookRelayTelemetry object.
+func NewWebhookRelayTelemetry() WebhookRelayTelemetry {
+	return WebhookRelayTelemetry{
+		Received:        NewInternalCollectorWebhookRelayReceived(),
+		ForwardDuration: NewInternalCollectorWebhookRelayForwardDuration(),
+		ForwardFailures: NewInternalCollectorWebhookRelayForwardFailures(),
+	}
+}
+
+// Collectors returns the list of collectors to register.
+func (t WebhookRelayTelemetry) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{t.Received, t.ForwardDuration, t.ForwardFailures}
+}
+
+// IncReceived counts a webhook delivery to relay.
+func (t WebhookRelayTelemetry) IncReceived(d schemas.WebhookDelivery) {
+	t.Received.(*prometheus.CounterVec).
+		With(prometheus.Labels{"source": d.Source, "event": d.Event, "repository": d.Repository}).
+		Inc()
+}
+
+// ObserveForward records the outcome of a forwarding attempt.
+func (t WebhookRelayTelemetry) ObserveForward(outcome string, d time.Duration) {
+	t.ForwardDuration.(*prometheus.HistogramVec).
+		With(prometheus.Labels{"outcome": outcome}).
+		Observe(d.Seconds())
+
+	if outcome != WebhookRelayOutcomeForwarded {
+		t.ForwardFailures.(*prometheus.CounterVec).
+			With(prometheus.Labels{"reason": outcome}).
+			Inc()
+	}
+}
+
+// webhookRepository extracts the repository the GitHub or GitLab event relates to, if any.
+func webhookRepository(body []byte) string {
+	var payload struct {
+		Repository struct {
+			FullName string `json:"full_name"`
+		} `json:"repository"`
+		Project struct {
+			PathWithNamespace string `json:"path_with_namespace"`
+		} `json:"project"`
+	}
+
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return ""
+	}
+
+	if payload.Repository.FullName != "" {
+		return payload.Repository.FullName
+	}
+
+	return payload.Project.PathWithNamespace
+}
+
+// webhookRelayURL returns the URL the webhook deliveries are forwarded to.
+func webhookRelayURL(cfg config.Config) (string, error) {
+	if cfg.Server.Webhook.Relay.URL != "" {
+		return cfg.Server.Webhook.Relay.URL, nil
+	}
+
+	return url.JoinPath(cfg.Clients.MendRenovate.URL, "/webhook")
+}
+
+// webhookRelaySchedule returns the scheduling of the buffered webhook deliveries relaying.
+func webhookRelaySchedule(cfg config.Config) config.SchedulerConfig {
+	return config.SchedulerConfig{
+		Scheduled:       true,
+		IntervalSeconds: cfg.Server.Webhook.Relay.RetryIntervalSeconds,
+	}
+}
+
+// ConfigureWebhookRelay registers and schedules the task relaying the buffered webhook deliveries.
+func (c *Controller) ConfigureWebhookRelay(ctx context.Context) {
+	c.RegisterTasks(schemas.TaskTypeRelayWebhookDeliveries, c.taskHandlerRelayWebhookDeliveries)
+	c.Schedule(ctx, schemas.TaskTypeRelayWebhookDeliveries, webhookRelaySchedule(c.Config()))
+}
+
+// relayWebhook forwards the delivery to Mend Renovate, it gets buffered in the store if Mend Renovate is unavailable.
+func (c *Controller) relayWebhook(ctx context.Context, d schemas.WebhookDelivery) {
+	c.WebhookRelayTelemetry.IncReceived(d)
+
+	retry, err := c.forwardWebhookDelivery(ctx, d)
+	if err == nil {
+		return
+	}
+
+	logger := log.WithContext(ctx).
+		WithFields(
+			log.Fields{
+				"source":     d.Source,
+				"event":      d.Event,
+				"repository": d.Repository,
+			},
+		).
+		WithError(err)
+
+	maxBufferedDeliveries := int64(c.Config().Server.Webhook.Relay.MaxBufferedDeliveries)
+	if !retry || maxBufferedDeliveries <= 0 {
+		logger.Warn("relaying webhook delivery, dropping it")
+
+		return
+	}
+
+	if err := c.Store.BufferWebhookDelivery(ctx, d, maxBufferedDeliveries); err != nil {
+		logger.WithError(err).Error("buffering webhook delivery, dropping it")
+
+		return
+	}
+
+	logger.Warn("relaying webhook delivery, buffered for later")
+}
+
+// forwardWebhookDelivery sends the delivery to Mend Renovate, retry tells whether it is worth trying again on error.
+func (c *Controller) forwardWebhookDelivery(ctx context.Context, d schemas.WebhookDelivery) (retry bool, err error) {
+	ctx, span := otel.Tracer(c.Config().OpenTelemetry.ServiceNameKey).Start(ctx, "controller:forwardWebhookDelivery")
+	defer span.End()
+
+	cfg := c.Config()
+
+	u, err := webhookRelayURL(cfg)
+	if err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(d.Body))
+	if err != nil {
+		return false, err
+	}
+
+	req.Header = d.Header.Clone()
+	for _, h := range hopByHopHeaders {
+		req.Header.Del(h)
+	}
+
+	client := &http.Client{
+		Timeout: time.Duration(cfg.Server.Webhook.Relay.TimeoutSeconds) * time.Second,
+	}
+
+	start := time.Now()
+
+	res, err := client.Do(req)
+	if err != nil {
+		c.WebhookRelayTelemetry.ObserveForward(WebhookRelayOutcomeUnavailable, time.Since(start))
+
+		return true, err
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode >= http.StatusInternalServerError:
+		c.WebhookRelayTelemetry.ObserveForward(WebhookRelayOutcomeUnavailable, time.Since(start))
+
+		return true, fmt.Errorf("mend renovate responded with status %d", res.StatusCode)
+	case res.StatusCode >= http.StatusBadRequest:
+		c.WebhookRelayTelemetry.ObserveForward(WebhookRelayOutcomeRejected, time.Since(start))
+
+		return false, fmt.Errorf("mend renovate rejected the delivery with status %d", res.StatusCode)
+	}
+
+	c.WebhookRelayTelemetry.ObserveForward(WebhookRelayOutcomeForwarded, time.Since(start))
+
+	return false, nil
+}
+
+// taskHandlerRelayWebhookDeliveries forwards the buffered deliveries, oldest first, until Mend Renovate becomes unavailable.
+func (c *Controller) taskHandlerRelayWebhookDeliveries(ctx context.Context) error {
+	defer c.UnqueueTask(ctx, schemas.TaskTypeRelayWebhookDeliveries, "_")
+	defer c.MonitorLastTaskScheduling(ctx, schemas.TaskTypeRelayWebhookDeliveries)
+
+	for {
+		d, ok, err := c.Store.PopWebhookDelivery(ctx)
+		if err != nil || !ok {
+			return err
+		}
+
+		retry, err := c.forwardWebhookDelivery(ctx, d)
+		if err == nil {
+			continue
+		}
+
+		logger := log.WithContext(ctx).
+			WithFields(
+				log.Fields{
+					"source":      d.Source,
+					"event":       d.Event,
+					"repository":  d.Repository,
+					"received-at": d.ReceivedAt,
+				},
+			).
+			WithError(err)
+
+		if !retry {
+			logger.Warn("relaying buffered webhook delivery, dropping it")
+
+			continue
+		}
+
+		// Mend Renovate is still unavailable, we will try again on the next run
+		if err := c.Store.RequeueWebhookDelivery(ctx, d); err != nil {
+			logger.WithError(err).Error("requeueing webhook delivery, dropping it")
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/config"
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/schemas"
+)
+
+func TestRelayWebhook(t *testing.T) {
+	ctx := context.Background()
+
+	// Stands in for Mend Renovate
+	var (
+		status   atomic.Int32
+		received = make(chan *http.Request, 10)
+		bodies   = make(chan string, 10)
+	)
+
+	renovate := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- string(b)
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer renovate.Close()
+
+	cfg := config.New()
+	cfg.Server.Webhook.Relay.Enabled = true
+	cfg.Server.Webhook.Relay.URL = renovate.URL + "/webhook"
+	cfg.Server.Webhook.Relay.MaxBufferedDeliveries = 10
+
+	c, err := New(ctx, cfg, "test")
+	require.NoError(t, err)
+
+	delivery := func(event string) schemas.WebhookDelivery {
+		return schemas.WebhookDelivery{
+			Source:     WebhookSourceGitHub,
+			Event:      event,
+			Repository: "xnok/foo",
+			Header: http.Header{
+				"X-Github-Event":      []string{event},
+				"X-Hub-Signature-256": []string{"sha256=abc"},
+				"Connection":          []string{"close"},
+			},
+			Body:       []byte(`{"event":"` + event + `"}`),
+			ReceivedAt: time.Now(),
+		}
+	}
+
+	bufferedCount := func() int64 {
+		count, err := c.Store.BufferedWebhookDeliveriesCount(ctx)
+		require.NoError(t, err)
+
+		return count
+	}
+
+	// Forwarded unchanged
+	status.Store(http.StatusOK)
+	c.relayWebhook(ctx, delivery("push"))
+
+	r := <-received
+	assert.Equal(t, "/webhook", r.URL.Path)
+	assert.Equal(t, "push", r.Header.Get("X-Github-Event"))
+	assert.Equal(t, "sha256=abc", r.Header.Get("X-Hub-Signature-256"))
+	assert.Equal(t, `{"event":"push"}`, <-bodies)
+	assert.Equal(t, int64(0), bufferedCount())
+
+	// Buffered whilst Mend Renovate is unavailable
+	status.Store(http.StatusServiceUnavailable)
+	c.relayWebhook(ctx, delivery("pull_request"))
+	c.relayWebhook(ctx, delivery("issues"))
+	<-received
+	<-received
+	<-bodies
+	<-bodies
+	assert.Equal(t, int64(2), bufferedCount())
+
+	// Kept in the buffer if still unavailable
+	require.NoError(t, c.taskHandlerRelayWebhookDeliveries(ctx))
+	<-received
+	assert.Equal(t, `{"event":"pull_request"}`, <-bodies)
+	assert.Equal(t, int64(2), bufferedCount())
+
+	// Relayed in order once available again
+	status.Store(http.StatusOK)
+	require.NoError(t, c.taskHandlerRelayWebhookDeliveries(ctx))
+	<-received
+	assert.Equal(t, `{"event":"pull_request"}`, <-bodies)
+	<-received
+	assert.Equal(t, `{"event":"issues"}`, <-bodies)
+	assert.Equal(t, int64(0), bufferedCount())
+
+	// Not buffered when rejected
+	status.Store(http.StatusBadRequest)
+	c.relayWebhook(ctx, delivery("push"))
+	<-received
+	<-bodies
+	assert.Equal(t, int64(0), bufferedCount())
+}
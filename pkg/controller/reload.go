@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/config"
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/schemas"
+)
+
+// ConfigReloadTelemetry holds the collectors reporting the outcome of the config reloads.
+type ConfigReloadTelemetry struct {
+	LastReloadSuccess          prometheus.Collector
+	LastReloadSuccessTimestamp prometheus.Collector
+}
+
+// NewConfigReloadTelemetry initializes and returns a new ConfigReloadTelemetry object,
+// the config loaded at startup is considered as a successful reload.
+func NewConfigReloadTelemetry() ConfigReloadTelemetry {
+	t := ConfigReloadTelemetry{
+		LastReloadSuccess:          NewInternalCollectorConfigLastReloadSuccess(),
+		LastReloadSuccessTimestamp: NewInternalCollectorConfigLastReloadSuccessTimestamp(),
+	}
+
+	t.Record(true)
+
+	return t
+}
+
+// Collectors returns the list of collectors to register.
+func (t ConfigReloadTelemetry) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{t.LastReloadSuccess, t.LastReloadSuccessTimestamp}
+}
+
+// Record records the outcome of a reload attempt.
+func (t ConfigReloadTelemetry) Record(success bool) {
+	if !success {
+		t.LastReloadSuccess.(*prometheus.GaugeVec).With(prometheus.Labels{}).Set(0)
+
+		return
+	}
+
+	t.LastReloadSuccess.(*prometheus.GaugeVec).With(prometheus.Labels{}).Set(1)
+	t.LastReloadSuccessTimestamp.(*prometheus.GaugeVec).With(prometheus.Labels{}).Set(float64(time.Now().Unix()))
+}
+
+// Reload validates the given config and swaps it with the current one, the
+// tasks whose scheduling changed get their tickers restarted. The current
+// config is kept if the new one is not valid.
+func (c *Controller) Reload(ctx context.Context, cfg config.Config) error {
+	ctx, span := otel.Tracer(c.Config().OpenTelemetry.ServiceNameKey).Start(ctx, "controller:Reload")
+	defer span.End()
+
+	if err := cfg.Validate(); err != nil {
+		c.ConfigReloadTelemetry.Record(false)
+
+		return err
+	}
+
+	previous := c.Config()
+
+	for name, changed := range map[string]bool{
+		"redis":                             previous.Redis != cfg.Redis,
+		"opentelemetry":                     previous.OpenTelemetry != cfg.OpenTelemetry,
+		"server.listen_address":             previous.Server.ListenAddress != cfg.Server.ListenAddress,
+		"server.enable_pprof":               previous.Server.EnablePprof != cfg.Server.EnablePprof,
+		"server.metrics.enabled":            previous.Server.Metrics.Enabled != cfg.Server.Metrics.Enabled,
+		"server.webhook.enabled":            previous.Server.Webhook.Enabled != cfg.Server.Webhook.Enabled,
+		"server.webhook.relay.enabled":      previous.Server.Webhook.Relay.Enabled != cfg.Server.Webhook.Relay.Enabled,
+		"server.admin.enabled":              previous.Server.Admin.Enabled != cfg.Server.Admin.Enabled,
+		"scheduler.maximum_jobs_queue_size": previous.Scheduler.MaximumJobsQueueSize != cfg.Scheduler.MaximumJobsQueueSize,
+		"sources":                           !reflect.DeepEqual(previous.Sources, cfg.Sources),
+	} {
+		if changed {
+			log.WithContext(ctx).
+				WithField("setting", name).
+				Warn("config setting changed, a restart is required for it to be taken into account")
+		}
+	}
+
+	c.cfg.Store(&cfg)
+
+	for _, s := range c.Sources {
+		for tt, sc := range s.Schedule() {
+			c.rescheduleTask(ctx, tt, sc)
+		}
+	}
+
+	if c.TaskController.TaskMap.Get(string(schemas.TaskTypeRelayWebhookDeliveries)) != nil {
+		c.rescheduleTask(ctx, schemas.TaskTypeRelayWebhookDeliveries, webhookRelaySchedule(cfg))
+	}
+
+	c.ConfigReloadTelemetry.Record(true)
+
+	log.WithContext(ctx).Info("config reloaded")
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/config"
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/schemas"
+)
+
+// testSource schedules a task after each of the pull and garbage collect settings.
+type testSource struct {
+	c *Controller
+}
+
+func (s testSource) Name() string { return "test" }
+
+func (s testSource) Tasks() map[schemas.TaskType]interface{} {
+	return map[schemas.TaskType]interface{}{
+		"pull": func() error { return nil },
+		"gc":   func() error { return nil },
+	}
+}
+
+func (s testSource) Schedule() map[schemas.TaskType]config.SchedulerConfig {
+	return map[schemas.TaskType]config.SchedulerConfig{
+		"pull": config.SchedulerConfig(s.c.Config().Pull.Metrics),
+		"gc":   config.SchedulerConfig(s.c.Config().GarbageCollect.Metrics),
+	}
+}
+
+func (s testSource) Collectors() RegistryCollectors { return RegistryCollectors{} }
+
+func (s testSource) Health(_ context.Context) error { return nil }
+
+// tickerOf returns the handle on the running ticker of the task, if any.
+func tickerOf(c *Controller, tt schemas.TaskType) (chan struct{}, bool) {
+	c.tickers.mutex.Lock()
+	defer c.tickers.mutex.Unlock()
+
+	t, ok := c.tickers.tickers[tt]
+
+	return t.done, ok
+}
+
+func newReloadTestController(t *testing.T, ctx context.Context) (*Controller, config.Config) {
+	cfg := config.New()
+	cfg.Pull.Metrics.OnInit = false
+
+	c, err := New(ctx, cfg, "test")
+	require.NoError(t, err)
+
+	s := testSource{c: &c}
+	for tt, h := range s.Tasks() {
+		c.RegisterTasks(tt, h)
+	}
+
+	c.Sources = append(c.Sources, s)
+
+	for tt, sc := range s.Schedule() {
+		c.Schedule(ctx, tt, sc)
+	}
+
+	return &c, cfg
+}
+
+func TestReload(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c, cfg := newReloadTestController(t, ctx)
+
+	pull, ok := tickerOf(c, "pull")
+	require.True(t, ok)
+	gc, ok := tickerOf(c, "gc")
+	require.True(t, ok)
+
+	// The config is read concurrently by the running tasks and handlers
+	stop := make(chan struct{})
+	readerDone := make(chan struct{})
+
+	go func() {
+		defer close(readerDone)
+
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				interval := c.Config().Pull.Metrics.IntervalSeconds
+				assert.Contains(t, []int{30, 60}, interval)
+			}
+		}
+	}()
+
+	cfg.Pull.Metrics.IntervalSeconds = 60
+	cfg.Log.Level = "debug"
+	require.NoError(t, c.Reload(ctx, cfg))
+
+	close(stop)
+	<-readerDone
+
+	// The new config is visible at once
+	assert.Equal(t, 60, c.Config().Pull.Metrics.IntervalSeconds)
+	assert.Equal(t, "debug", c.Config().Log.Level)
+
+	// Only the ticker of the task whose scheduling changed got restarted
+	newPull, ok := tickerOf(c, "pull")
+	require.True(t, ok)
+	assert.NotEqual(t, pull, newPull)
+
+	select {
+	case <-pull:
+	default:
+		t.Error("the previous pull ticker is still running")
+	}
+
+	newGC, ok := tickerOf(c, "gc")
+	require.True(t, ok)
+	assert.Equal(t, gc, newGC)
+
+	// Unscheduled tasks get their ticker stopped
+	cfg.GarbageCollect.Metrics.Scheduled = false
+	require.NoError(t, c.Reload(ctx, cfg))
+
+	_, ok = tickerOf(c, "gc")
+	assert.False(t, ok)
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(c.ConfigReloadTelemetry.LastReloadSuccess.(*prometheus.GaugeVec).With(prometheus.Labels{})))
+}
+
+func TestReloadInvalidConfig(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c, cfg := newReloadTestController(t, ctx)
+
+	pull, ok := tickerOf(c, "pull")
+	require.True(t, ok)
+
+	cfg.Pull.Metrics.IntervalSeconds = 0
+	assert.Error(t, c.Reload(ctx, cfg))
+
+	// The current config and scheduling are kept
+	assert.Equal(t, 30, c.Config().Pull.Metrics.IntervalSeconds)
+
+	current, ok := tickerOf(c, "pull")
+	require.True(t, ok)
+	assert.Equal(t, pull, current)
+
+	assert.Equal(t, float64(0), testutil.ToFloat64(c.ConfigReloadTelemetry.LastReloadSuccess.(*prometheus.GaugeVec).With(prometheus.Labels{})))
+}
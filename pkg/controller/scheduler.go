@@ -2,6 +2,7 @@ package controller
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -19,10 +20,10 @@ import (
 
 // TaskController holds task related metrics.
 type TaskController struct {
-	Factory                  taskq.Factory
-	Queue                    taskq.Queue
-	TaskMap                  *taskq.TaskMap
-	TaskSchedulingMonitoring map[schemas.TaskType]*schemas.TaskSchedulingStatus
+	Factory   taskq.Factory
+	Queue     taskq.Queue
+	TaskMap   *taskq.TaskMap
+	Telemetry TaskTelemetry
 }
 
 // NewTaskController initializes and returns a new TaskController object.
@@ -64,16 +65,40 @@ func NewTaskController(ctx context.Context, r *redis.Client, cfg config.Config)
 		}
 	}
 
-	t.TaskSchedulingMonitoring = make(map[schemas.TaskType]*schemas.TaskSchedulingStatus)
+	t.Telemetry = NewTaskTelemetry()
 
 	return
 }
 
+// taskTicker is a handle on a running task scheduling goroutine.
+type taskTicker struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// taskTickers keeps track of the tasks scheduling in order to be able to update it at runtime.
+type taskTickers struct {
+	mutex     sync.Mutex
+	tickers   map[schemas.TaskType]taskTicker
+	schedules map[schemas.TaskType]config.SchedulerConfig
+}
+
+func newTaskTickers() *taskTickers {
+	return &taskTickers{
+		tickers:   make(map[schemas.TaskType]taskTicker),
+		schedules: make(map[schemas.TaskType]config.SchedulerConfig),
+	}
+}
+
 // Schedule ..
 func (c *Controller) Schedule(ctx context.Context, tt schemas.TaskType, cfg config.SchedulerConfig) {
-	ctx, span := otel.Tracer(c.Config.OpenTelemetry.ServiceNameKey).Start(ctx, "controller:Schedule")
+	ctx, span := otel.Tracer(c.Config().OpenTelemetry.ServiceNameKey).Start(ctx, "controller:Schedule")
 	defer span.End()
 
+	c.tickers.mutex.Lock()
+	c.tickers.schedules[tt] = cfg
+	c.tickers.mutex.Unlock()
+
 	if cfg.OnInit {
 		c.ScheduleTask(ctx, tt, "_")
 	}
@@ -81,15 +106,18 @@ func (c *Controller) Schedule(ctx context.Context, tt schemas.TaskType, cfg conf
 	if cfg.Scheduled {
 		c.ScheduleTaskWithTicker(ctx, tt, cfg.IntervalSeconds)
 	}
+}
 
-	if c.Redis != nil {
-		c.ScheduleRedisSetKeepalive(ctx)
-	}
+// ScheduleTask enqueues the task, it returns the reason for which the task
+// did not get scheduled or an empty string if it did.
+func (c *Controller) ScheduleTask(ctx context.Context, tt schemas.TaskType, uniqueID string, args ...interface{}) (skipReason string) {
+	return c.scheduleTask(ctx, tt, uniqueID, "", args...)
 }
 
-// ScheduleTask ..
-func (c *Controller) ScheduleTask(ctx context.Context, tt schemas.TaskType, uniqueID string, args ...interface{}) {
-	ctx, span := otel.Tracer(c.Config.OpenTelemetry.ServiceNameKey).Start(ctx, "controller:ScheduleTask")
+// scheduleTask enqueues the task, the outcome of its execution gets recorded
+// in the store under the execution ID if one is provided.
+func (c *Controller) scheduleTask(ctx context.Context, tt schemas.TaskType, uniqueID, executionID string, args ...interface{}) (skipReason string) {
+	ctx, span := otel.Tracer(c.Config().OpenTelemetry.ServiceNameKey).Start(ctx, "controller:ScheduleTask")
 	defer span.End()
 
 	span.SetAttributes(attribute.String("task_type", string(tt)))
@@ -100,39 +128,43 @@ func (c *Controller) ScheduleTask(ctx context.Context, tt schemas.TaskType, uniq
 		"task_unique_id": uniqueID,
 	}
 	task := c.TaskController.TaskMap.Get(string(tt))
-	msg := task.NewJob(args...)
+	msg := newTaskJob(task, executionID, args...)
 
 	qlen, err := c.TaskController.Queue.Len(ctx)
 	if err != nil {
+		c.TaskController.Telemetry.IncSkipped(TaskSkippedReasonQueueError)
 		log.WithContext(ctx).
 			WithFields(logFields).
 			Warn("unable to read task queue length, skipping scheduling of task..")
 
-		return
+		return TaskSkippedReasonQueueError
 	}
 
 	if qlen >= c.TaskController.Queue.Options().BufferSize {
+		c.TaskController.Telemetry.IncSkipped(TaskSkippedReasonQueueFull)
 		log.WithContext(ctx).
 			WithFields(logFields).
 			Warn("queue buffer size exhausted, skipping scheduling of task..")
 
-		return
+		return TaskSkippedReasonQueueFull
 	}
 
 	queued, err := c.Store.QueueTask(ctx, tt, uniqueID, c.UUID.String())
 	if err != nil {
+		c.TaskController.Telemetry.IncSkipped(TaskSkippedReasonStoreError)
 		log.WithContext(ctx).
 			WithFields(logFields).
 			Warn("unable to declare the queueing, skipping scheduling of task..")
 
-		return
+		return TaskSkippedReasonStoreError
 	}
 
 	if !queued {
+		c.TaskController.Telemetry.IncSkipped(TaskSkippedReasonAlreadyQueued)
 		log.WithFields(logFields).
 			Debug("task already queued, skipping scheduling of task..")
 
-		return
+		return TaskSkippedReasonAlreadyQueued
 	}
 
 	go func(job *taskq.Job) {
@@ -142,11 +174,13 @@ func (c *Controller) ScheduleTask(ctx context.Context, tt schemas.TaskType, uniq
 				Warn("scheduling task")
 		}
 	}(msg)
+
+	return ""
 }
 
 // ScheduleTaskWithTicker ..
 func (c *Controller) ScheduleTaskWithTicker(ctx context.Context, tt schemas.TaskType, intervalSeconds int) {
-	ctx, span := otel.Tracer(c.Config.OpenTelemetry.ServiceNameKey).Start(ctx, "controller:ScheduleTaskWithTicker")
+	ctx, span := otel.Tracer(c.Config().OpenTelemetry.ServiceNameKey).Start(ctx, "controller:ScheduleTaskWithTicker")
 	defer span.End()
 	span.SetAttributes(attribute.String("task_type", string(tt)))
 	span.SetAttributes(attribute.Int("interval_seconds", intervalSeconds))
@@ -166,10 +200,33 @@ func (c *Controller) ScheduleTaskWithTicker(ctx context.Context, tt schemas.Task
 		},
 	).Debug("task scheduled")
 
-	c.TaskController.MonitorNextTaskScheduling(tt, intervalSeconds)
+	c.MonitorNextTaskScheduling(ctx, tt, intervalSeconds)
+
+	// Replace the ticker of the task if it was already scheduled
+	c.stopTaskTicker(tt)
+
+	ctx, cancel := context.WithCancel(ctx)
+	ticker := taskTicker{
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	c.tickers.mutex.Lock()
+	c.tickers.tickers[tt] = ticker
+	c.tickers.mutex.Unlock()
+
+	c.schedulers.Add(1)
 
 	go func(ctx context.Context) {
-		ticker := time.NewTicker(time.Duration(intervalSeconds) * time.Second)
+		defer c.schedulers.Done()
+		defer close(ticker.done)
+
+		interval := time.Duration(intervalSeconds) * time.Second
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		c.schedulerHeartbeats.Beat(string(tt), interval)
+		defer c.schedulerHeartbeats.Stop(string(tt))
 
 		for {
 			select {
@@ -178,22 +235,78 @@ func (c *Controller) ScheduleTaskWithTicker(ctx context.Context, tt schemas.Task
 
 				return
 			case <-ticker.C:
-				c.ScheduleTask(ctx, tt, "_")
-				c.TaskController.MonitorNextTaskScheduling(tt, intervalSeconds)
+				c.schedulerHeartbeats.Beat(string(tt), interval)
+
+				if !c.taskSchedulingPaused(ctx, tt) {
+					c.ScheduleTask(ctx, tt, "_")
+				}
+
+				c.MonitorNextTaskScheduling(ctx, tt, intervalSeconds)
 			}
 		}
 	}(ctx)
 }
 
+// stopTaskTicker stops the periodic scheduling of the task and waits for it to exit.
+func (c *Controller) stopTaskTicker(tt schemas.TaskType) {
+	c.tickers.mutex.Lock()
+	ticker, ok := c.tickers.tickers[tt]
+	delete(c.tickers.tickers, tt)
+	c.tickers.mutex.Unlock()
+
+	if ok {
+		ticker.cancel()
+		<-ticker.done
+	}
+}
+
+// rescheduleTask applies a new scheduling configuration to the task, its ticker
+// is only restarted if the configuration changed.
+func (c *Controller) rescheduleTask(ctx context.Context, tt schemas.TaskType, cfg config.SchedulerConfig) {
+	c.tickers.mutex.Lock()
+	previous, ok := c.tickers.schedules[tt]
+	c.tickers.schedules[tt] = cfg
+	c.tickers.mutex.Unlock()
+
+	if ok && previous == cfg {
+		return
+	}
+
+	log.WithField("task", tt).
+		WithFields(cfg.Log()).
+		Info("task scheduling updated")
+
+	c.stopTaskTicker(tt)
+
+	if cfg.Scheduled {
+		c.ScheduleTaskWithTicker(ctx, tt, cfg.IntervalSeconds)
+	}
+}
+
 // ScheduleRedisSetKeepalive will ensure that whilst the process is running,
 // a key is periodically updated within Redis to let other instances know this
 // one is alive and processing tasks.
 func (c *Controller) ScheduleRedisSetKeepalive(ctx context.Context) {
-	ctx, span := otel.Tracer(c.Config.OpenTelemetry.ServiceNameKey).Start(ctx, "controller:ScheduleRedisSetKeepalive")
+	ctx, span := otel.Tracer(c.Config().OpenTelemetry.ServiceNameKey).Start(ctx, "controller:ScheduleRedisSetKeepalive")
 	defer span.End()
 
+	// The keepalive and the replica are published right away, not to be
+	// considered dead by the other processes until the first tick
+	if !c.setRedisKeepalive(ctx) {
+		return
+	}
+
+	c.schedulers.Add(1)
+
 	go func(ctx context.Context) {
-		ticker := time.NewTicker(time.Duration(5) * time.Second)
+		defer c.schedulers.Done()
+
+		interval := time.Duration(5) * time.Second
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		c.schedulerHeartbeats.Beat("redis_keepalive", interval)
+		defer c.schedulerHeartbeats.Stop("redis_keepalive")
 
 		for {
 			select {
@@ -202,28 +315,54 @@ func (c *Controller) ScheduleRedisSetKeepalive(ctx context.Context) {
 
 				return
 			case <-ticker.C:
-				if _, err := c.Store.(*store.Redis).SetKeepalive(ctx, c.UUID.String(), time.Duration(10)*time.Second); err != nil {
-					log.WithContext(ctx).
-						WithError(err).
-						Fatal("setting keepalive")
+				c.schedulerHeartbeats.Beat("redis_keepalive", interval)
+
+				if !c.setRedisKeepalive(ctx) {
+					return
 				}
 			}
 		}
 	}(ctx)
 }
 
-func (tc *TaskController) MonitorNextTaskScheduling(tt schemas.TaskType, duration int) {
-	if _, ok := tc.TaskSchedulingMonitoring[tt]; !ok {
-		tc.TaskSchedulingMonitoring[tt] = &schemas.TaskSchedulingStatus{}
+// redisKeepaliveTTL is how long the keepalive and the replica of the process last unless refreshed.
+const redisKeepaliveTTL = 10 * time.Second
+
+// setRedisKeepalive sets the keepalive of the process and publishes its replica,
+// it returns false if the process is being shut down.
+func (c *Controller) setRedisKeepalive(ctx context.Context) bool {
+	if _, err := c.Store.(*store.Redis).SetKeepalive(ctx, c.UUID.String(), redisKeepaliveTTL); err != nil {
+		// The keepalive is removed as part of the shutdown process
+		if ctx.Err() != nil {
+			return false
+		}
+
+		log.WithContext(ctx).
+			WithError(err).
+			Fatal("setting keepalive")
 	}
 
-	tc.TaskSchedulingMonitoring[tt].Next = time.Now().Add(time.Duration(duration) * time.Second)
+	c.publishReplica(ctx, redisKeepaliveTTL)
+
+	return true
 }
 
-func (tc *TaskController) MonitorLastTaskScheduling(tt schemas.TaskType) {
-	if _, ok := tc.TaskSchedulingMonitoring[tt]; !ok {
-		tc.TaskSchedulingMonitoring[tt] = &schemas.TaskSchedulingStatus{}
+// MonitorNextTaskScheduling records when the task is next going to be scheduled.
+func (c *Controller) MonitorNextTaskScheduling(ctx context.Context, tt schemas.TaskType, duration int) {
+	if err := c.Store.SetTaskSchedulingNext(ctx, tt, time.Now().Add(time.Duration(duration)*time.Second)); err != nil {
+		log.WithContext(ctx).
+			WithField("task_type", tt).
+			WithError(err).
+			Warn("recording next task scheduling")
 	}
+}
 
-	tc.TaskSchedulingMonitoring[tt].Last = time.Now()
+// MonitorLastTaskScheduling records that the task has just been executed.
+func (c *Controller) MonitorLastTaskScheduling(ctx context.Context, tt schemas.TaskType) {
+	if err := c.Store.SetTaskSchedulingLast(ctx, tt, time.Now()); err != nil {
+		log.WithContext(ctx).
+			WithField("task_type", tt).
+			WithError(err).
+			Warn("recording last task scheduling")
+	}
 }
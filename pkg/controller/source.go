@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"context"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/config"
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/schemas"
+)
+
+// Source is a provider of metrics, it declares the tasks used to fetch
+// its data and the collectors used to export it.
+type Source interface {
+	// Name identifies the source, it is used to enable or disable it from the config
+	Name() string
+
+	// Tasks returns the handlers of the tasks fetching the data
+	Tasks() map[schemas.TaskType]interface{}
+
+	// Schedule returns how each task should be scheduled
+	Schedule() map[schemas.TaskType]config.SchedulerConfig
+
+	// Collectors returns new collectors for the metrics exported by the source
+	Collectors() RegistryCollectors
+
+	// Health returns an error when the source is not able to fetch its data
+	Health(ctx context.Context) error
+}
+
+// SourceFactory instantiates a Source bound to the given controller.
+type SourceFactory func(c *Controller) Source
+
+var (
+	sourceFactories      []SourceFactory
+	sourceFactoriesMutex sync.Mutex
+)
+
+// RegisterSource makes a source available to the controllers,
+// it is meant to be called from the init function of the package implementing it.
+func RegisterSource(f SourceFactory) {
+	sourceFactoriesMutex.Lock()
+	defer sourceFactoriesMutex.Unlock()
+
+	sourceFactories = append(sourceFactories, f)
+}
+
+// RegisterSources instantiates the enabled sources and registers their tasks and collectors,
+// without scheduling them.
+func (c *Controller) RegisterSources(ctx context.Context) {
+	sourceFactoriesMutex.Lock()
+	defer sourceFactoriesMutex.Unlock()
+
+	for _, f := range sourceFactories {
+		s := f(c)
+
+		if !c.Config().SourceEnabled(s.Name()) {
+			log.WithField("source", s.Name()).Info("source disabled, skipping")
+
+			continue
+		}
+
+		for tt, h := range s.Tasks() {
+			c.RegisterTasks(tt, h)
+		}
+
+		c.RegisterCollector(ctx, s.Collectors)
+		c.Sources = append(c.Sources, s)
+
+		log.WithField("source", s.Name()).Info("source configured")
+	}
+}
+
+// ConfigureSources registers the enabled sources and schedules their tasks.
+func (c *Controller) ConfigureSources(ctx context.Context) {
+	c.RegisterSources(ctx)
+
+	for _, s := range c.Sources {
+		for tt, cfg := range s.Schedule() {
+			c.Schedule(ctx, tt, cfg)
+		}
+	}
+
+	if c.Redis != nil {
+		c.ScheduleRedisSetKeepalive(ctx)
+	}
+}
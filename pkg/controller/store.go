@@ -34,6 +34,15 @@ func StoreSetMetric(ctx context.Context, s store.Store, m schemas.Metric) {
 	}
 }
 
+func StoreIncMetric(ctx context.Context, s store.Store, m schemas.Metric) {
+	if err := s.IncMetric(ctx, m); err != nil {
+		log.WithContext(ctx).
+			WithFields(metricLogFields(m)).
+			WithError(err).
+			Errorf("incrementing metric in the store")
+	}
+}
+
 func StoreDelMetric(ctx context.Context, s store.Store, m schemas.Metric) {
 	if err := s.DelMetric(ctx, m.Key()); err != nil {
 		log.WithContext(ctx).
@@ -0,0 +1,263 @@
+package controller
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	"github.com/vmihailenco/msgpack/v5"
+	"github.com/vmihailenco/taskq/v4"
+
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/schemas"
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/store"
+)
+
+// List of the reasons for which a task may not get scheduled.
+const (
+	TaskSkippedReasonQueueError    = "queue_error"
+	TaskSkippedReasonQueueFull     = "queue_full"
+	TaskSkippedReasonAlreadyQueued = "already_queued"
+	TaskSkippedReasonStoreError    = "store_error"
+	TaskSkippedReasonPaused        = "paused"
+)
+
+// List of the outcomes of a task execution.
+const (
+	TaskOutcomeSuccess = "success"
+	TaskOutcomeError   = "error"
+)
+
+// TaskTelemetry holds the collectors instrumenting the task executions.
+// Unlike the other internal collectors, they are kept for the lifetime of
+// the process as their values cannot be computed from the store.
+type TaskTelemetry struct {
+	Duration  prometheus.Collector
+	QueueWait prometheus.Collector
+	Skipped   prometheus.Collector
+
+	// consecutiveErrors mirrors the counter used by the queue consumer to pause itself
+	consecutiveErrors *atomic.Uint32
+
+	// lastExecuted holds the last task executed by this process
+	lastExecuted *atomic.Pointer[taskExecution]
+
+	// running counts the tasks being executed by this process
+	running *runningTasks
+}
+
+// taskExecution describes the execution of a task.
+type taskExecution struct {
+	tt schemas.TaskType
+	at time.Time
+}
+
+// NewTaskTelemetry initializes and returns a new TaskTelemetry object.
+func NewTaskTelemetry() TaskTelemetry {
+	return TaskTelemetry{
+		Duration:  NewInternalCollectorTaskDuration(),
+		QueueWait: NewInternalCollectorTaskQueueWait(),
+		Skipped:   NewInternalCollectorTaskSkipped(),
+
+		consecutiveErrors: &atomic.Uint32{},
+		lastExecuted:      &atomic.Pointer[taskExecution]{},
+		running: &runningTasks{
+			counts: make(map[schemas.TaskType]int),
+		},
+	}
+}
+
+// Collectors returns the list of collectors to register.
+func (t TaskTelemetry) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{t.Duration, t.QueueWait, t.Skipped}
+}
+
+// ObserveDuration records the execution time of a task.
+func (t TaskTelemetry) ObserveDuration(tt schemas.TaskType, outcome string, d time.Duration) {
+	t.Duration.(*prometheus.HistogramVec).
+		With(prometheus.Labels{"task_type": string(tt), "outcome": outcome}).
+		Observe(d.Seconds())
+}
+
+// ObserveQueueWait records the time a task spent in the queue.
+func (t TaskTelemetry) ObserveQueueWait(tt schemas.TaskType, d time.Duration) {
+	t.QueueWait.(*prometheus.HistogramVec).
+		With(prometheus.Labels{"task_type": string(tt)}).
+		Observe(d.Seconds())
+}
+
+// ConsecutiveErrors returns the number of task executions which failed in a row.
+func (t TaskTelemetry) ConsecutiveErrors() uint32 {
+	return t.consecutiveErrors.Load()
+}
+
+// LastExecuted returns the last task executed by this process and when it got executed.
+func (t TaskTelemetry) LastExecuted() (schemas.TaskType, time.Time) {
+	if e := t.lastExecuted.Load(); e != nil {
+		return e.tt, e.at
+	}
+
+	return "", time.Time{}
+}
+
+// RunningTasks returns the types of the tasks being executed by this process, sorted by name.
+func (t TaskTelemetry) RunningTasks() []schemas.TaskType {
+	return t.running.list()
+}
+
+// IncSkipped counts a task which could not be scheduled.
+func (t TaskTelemetry) IncSkipped(reason string) {
+	t.Skipped.(*prometheus.CounterVec).
+		With(prometheus.Labels{"reason": reason}).
+		Inc()
+}
+
+// runningTasks counts the tasks being executed, by type.
+type runningTasks struct {
+	mutex  sync.Mutex
+	counts map[schemas.TaskType]int
+}
+
+func (r *runningTasks) add(tt schemas.TaskType, delta int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.counts[tt] += delta; r.counts[tt] <= 0 {
+		delete(r.counts, tt)
+	}
+}
+
+func (r *runningTasks) list() (taskTypes []schemas.TaskType) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for tt := range r.counts {
+		taskTypes = append(taskTypes, tt)
+	}
+
+	sort.Slice(taskTypes, func(i, j int) bool { return taskTypes[i] < taskTypes[j] })
+
+	return
+}
+
+// taskExecutionTTL is how long the outcome of an awaited task execution is kept around.
+const taskExecutionTTL = 10 * time.Minute
+
+// newTaskJob creates a new job for the task, the enqueue time and the execution ID are
+// prepended to the arguments in order to be able to compute the queue wait and to record
+// the outcome once handled, potentially by another process. The outcome is only recorded
+// when an execution ID is provided.
+func newTaskJob(task *taskq.Task, executionID string, args ...interface{}) *taskq.Job {
+	return task.NewJob(append([]interface{}{time.Now().UnixNano(), executionID}, args...)...)
+}
+
+// instrumentedHandler wraps a task handler to record its telemetry.
+type instrumentedHandler struct {
+	tt        schemas.TaskType
+	handler   taskq.Handler
+	telemetry TaskTelemetry
+	store     store.Store
+
+	// publishRunningTasks is called whenever the handler starts and completes a job
+	publishRunningTasks func(context.Context)
+}
+
+// HandleJob implements taskq.Handler.
+func (h *instrumentedHandler) HandleJob(ctx context.Context, job *taskq.Job) error {
+	start := time.Now()
+
+	// We work on a copy in order to keep the original arguments in case the job gets retried
+	j := *job
+
+	enqueuedAt, executionID, err := popJobEnvelope(&j)
+	if err != nil {
+		return err
+	}
+
+	if enqueuedAt > 0 {
+		h.telemetry.ObserveQueueWait(h.tt, start.Sub(time.Unix(0, enqueuedAt)))
+	}
+
+	h.telemetry.running.add(h.tt, 1)
+	h.publishRunningTasks(ctx)
+
+	err = h.handler.HandleJob(ctx, &j)
+
+	h.telemetry.running.add(h.tt, -1)
+	h.publishRunningTasks(ctx)
+
+	outcome := TaskOutcomeSuccess
+	if err != nil {
+		outcome = TaskOutcomeError
+
+		h.telemetry.consecutiveErrors.Add(1)
+	} else {
+		h.telemetry.consecutiveErrors.Store(0)
+	}
+
+	h.telemetry.ObserveDuration(h.tt, outcome, time.Since(start))
+	h.telemetry.lastExecuted.Store(&taskExecution{tt: h.tt, at: start})
+
+	if executionID != "" {
+		e := schemas.TaskExecution{ID: executionID, TaskType: h.tt, Outcome: outcome}
+		if err != nil {
+			e.Error = err.Error()
+		}
+
+		if serr := h.store.SetTaskExecution(ctx, e, taskExecutionTTL); serr != nil {
+			log.WithContext(ctx).
+				WithError(serr).
+				WithField("task_type", h.tt).
+				Warn("recording task execution outcome")
+		}
+	}
+
+	return err
+}
+
+// popJobEnvelope removes the enqueue time and the execution ID set by newTaskJob from the job arguments and returns them.
+func popJobEnvelope(job *taskq.Job) (enqueuedAt int64, executionID string, err error) {
+	// In-memory jobs still hold their arguments as is
+	if job.Args != nil {
+		if len(job.Args) < 2 {
+			return
+		}
+
+		enqueuedAt, _ = job.Args[0].(int64)
+		executionID, _ = job.Args[1].(string)
+		job.Args = job.Args[2:]
+
+		return
+	}
+
+	// Otherwise they have been marshalled in order to be sent over to the queue
+	var b []byte
+
+	if b, err = job.MarshalArgs(); err != nil {
+		return
+	}
+
+	var args []msgpack.RawMessage
+	if err = msgpack.Unmarshal(b, &args); err != nil || len(args) < 2 {
+		return
+	}
+
+	if err = msgpack.Unmarshal(args[0], &enqueuedAt); err != nil {
+		return
+	}
+
+	if err = msgpack.Unmarshal(args[1], &executionID); err != nil {
+		return
+	}
+
+	if job.ArgsBin, err = msgpack.Marshal(args[2:]); err != nil {
+		return
+	}
+
+	job.ArgsCompression = ""
+
+	return
+}
@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmihailenco/taskq/v4"
+)
+
+func TestPopJobEnvelope(t *testing.T) {
+	var handledArg string
+
+	task, err := (&taskq.TaskMap{}).Register(
+		"test", &taskq.TaskConfig{
+			Handler: func(arg string) { handledArg = arg },
+		},
+	)
+	assert.NoError(t, err)
+
+	// In-memory job
+	job := newTaskJob(task, "bar", "foo")
+	enqueuedAt, executionID, err := popJobEnvelope(job)
+	assert.NoError(t, err)
+	assert.Greater(t, enqueuedAt, int64(0))
+	assert.Equal(t, "bar", executionID)
+	assert.Equal(t, []interface{}{"foo"}, job.Args)
+
+	// Marshalled job, as it comes out of redis
+	job = newTaskJob(task, "bar", "foo")
+	b, err := job.MarshalBinary()
+	assert.NoError(t, err)
+
+	unmarshalledJob := &taskq.Job{}
+	assert.NoError(t, unmarshalledJob.UnmarshalBinary(b))
+
+	enqueuedAt, executionID, err = popJobEnvelope(unmarshalledJob)
+	assert.NoError(t, err)
+	assert.Greater(t, enqueuedAt, int64(0))
+	assert.Equal(t, "bar", executionID)
+	assert.NoError(t, task.HandleJob(context.Background(), unmarshalledJob))
+	assert.Equal(t, "foo", handledArg)
+}
@@ -0,0 +1,202 @@
+package controller
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/schemas"
+)
+
+// List of the platforms webhooks can be received from.
+const (
+	WebhookSourceGitHub  = "github"
+	WebhookSourceGitLab  = "gitlab"
+	WebhookSourceUnknown = "unknown"
+)
+
+// List of the results of the webhook requests.
+const (
+	WebhookResultAccepted     = "accepted"
+	WebhookResultUnauthorized = "unauthorized"
+	WebhookResultInvalid      = "invalid"
+)
+
+// webhookMaxPayloadBytes matches the maximum payload size GitHub sends.
+const webhookMaxPayloadBytes = 25 << 20
+
+// WebhookTrigger can be implemented by the sources whose data should be refreshed upon webhook events.
+type WebhookTrigger interface {
+	// WebhookTasks returns the tasks to schedule when receiving a webhook event
+	WebhookTasks() []schemas.TaskType
+}
+
+// WebhookEvent is an authenticated webhook request.
+type WebhookEvent struct {
+	// Source is the platform the event comes from, eg: github or gitlab
+	Source string
+	// Event is the type of event, as stated by the platform headers
+	Event string
+	// Body is the raw payload of the event
+	Body []byte
+}
+
+// WebhookConsumer can be implemented by the sources computing metrics out of the webhook events.
+type WebhookConsumer interface {
+	// HandleWebhook processes an authenticated webhook event
+	HandleWebhook(ctx context.Context, e WebhookEvent) error
+}
+
+// WebhookTelemetry holds the collectors instrumenting the webhook requests.
+type WebhookTelemetry struct {
+	Requests prometheus.Collector
+}
+
+// NewWebhookTelemetry initializes and returns a new WebhookTelemetry object.
+func NewWebhookTelemetry() WebhookTelemetry {
+	return WebhookTelemetry{
+		Requests: NewInternalCollectorWebhookRequests(),
+	}
+}
+
+// Collectors returns the list of collectors to register.
+func (t WebhookTelemetry) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{t.Requests}
+}
+
+// IncRequests counts a webhook request.
+func (t WebhookTelemetry) IncRequests(source, event, result string) {
+	t.Requests.(*prometheus.CounterVec).
+		With(prometheus.Labels{"source": source, "event": event, "result": result}).
+		Inc()
+}
+
+// verifyWebhookRequest identifies the platform the request comes from and checks it is
+// authenticated with the secret token, either using a HMAC signature of the body (GitHub)
+// or as a shared secret (GitLab).
+func verifyWebhookRequest(r *http.Request, body []byte, secret string) (source, event string, ok bool) {
+	if secret == "" {
+		return WebhookSourceUnknown, "", false
+	}
+
+	if signature := r.Header.Get("X-Hub-Signature-256"); signature != "" {
+		expected, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+		if err != nil {
+			return WebhookSourceGitHub, "", false
+		}
+
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+
+		if !hmac.Equal(mac.Sum(nil), expected) {
+			return WebhookSourceGitHub, "", false
+		}
+
+		return WebhookSourceGitHub, r.Header.Get("X-GitHub-Event"), true
+	}
+
+	if token := r.Header.Get("X-Gitlab-Token"); token != "" {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+			return WebhookSourceGitLab, "", false
+		}
+
+		return WebhookSourceGitLab, r.Header.Get("X-Gitlab-Event"), true
+	}
+
+	return WebhookSourceUnknown, "", false
+}
+
+// webhookDebouncer coalesces the tasks scheduled by the webhooks received within the same window.
+type webhookDebouncer struct {
+	mutex   sync.Mutex
+	pending map[schemas.TaskType]*time.Timer
+	stopped bool
+}
+
+func newWebhookDebouncer() *webhookDebouncer {
+	return &webhookDebouncer{
+		pending: make(map[schemas.TaskType]*time.Timer),
+	}
+}
+
+// trigger runs f once the window is elapsed, unless it is already pending for the task.
+func (d *webhookDebouncer) trigger(tt schemas.TaskType, window time.Duration, f func()) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if _, ok := d.pending[tt]; ok || d.stopped {
+		return
+	}
+
+	d.pending[tt] = time.AfterFunc(window, func() {
+		d.mutex.Lock()
+		delete(d.pending, tt)
+		d.mutex.Unlock()
+
+		f()
+	})
+}
+
+// stop cancels the pending tasks and ignores the upcoming triggers.
+func (d *webhookDebouncer) stop() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	for tt, t := range d.pending {
+		t.Stop()
+		delete(d.pending, tt)
+	}
+
+	d.stopped = true
+}
+
+// scheduleWebhookTasks schedules the tasks of the sources to be refreshed upon webhook events.
+func (c *Controller) scheduleWebhookTasks(ctx context.Context) {
+	window := time.Duration(c.Config().Server.Webhook.DebounceSeconds) * time.Second
+
+	for _, s := range c.Sources {
+		t, ok := s.(WebhookTrigger)
+		if !ok {
+			continue
+		}
+
+		for _, tt := range t.WebhookTasks() {
+			tt := tt
+			c.webhookDebouncer.trigger(tt, window, func() {
+				c.ScheduleTask(ctx, tt, "_")
+			})
+		}
+	}
+}
+
+// consumeWebhook passes the event over to the sources consuming webhooks.
+func (c *Controller) consumeWebhook(ctx context.Context, e WebhookEvent) {
+	for _, s := range c.Sources {
+		consumer, ok := s.(WebhookConsumer)
+		if !ok {
+			continue
+		}
+
+		if err := consumer.HandleWebhook(ctx, e); err != nil {
+			log.WithContext(ctx).
+				WithFields(
+					log.Fields{
+						"source":         s.Name(),
+						"webhook-source": e.Source,
+						"webhook-event":  e.Event,
+					},
+				).
+				WithError(err).
+				Warn("handling webhook event")
+		}
+	}
+}
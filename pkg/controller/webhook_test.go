@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func githubSignature(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifyWebhookRequest(t *testing.T) {
+	body := `{"action":"opened"}`
+
+	tests := []struct {
+		name       string
+		secret     string
+		headers    map[string]string
+		wantSource string
+		wantEvent  string
+		wantOK     bool
+	}{
+		{
+			name:   "OK - GitHub signature",
+			secret: "secret",
+			headers: map[string]string{
+				"X-Hub-Signature-256": githubSignature("secret", body),
+				"X-GitHub-Event":      "pull_request",
+			},
+			wantSource: WebhookSourceGitHub,
+			wantEvent:  "pull_request",
+			wantOK:     true,
+		},
+		{
+			name:   "KO - GitHub signature with another secret",
+			secret: "secret",
+			headers: map[string]string{
+				"X-Hub-Signature-256": githubSignature("other", body),
+				"X-GitHub-Event":      "pull_request",
+			},
+			wantSource: WebhookSourceGitHub,
+		},
+		{
+			name:   "KO - GitHub malformed signature",
+			secret: "secret",
+			headers: map[string]string{
+				"X-Hub-Signature-256": "sha256=zz",
+			},
+			wantSource: WebhookSourceGitHub,
+		},
+		{
+			name:   "OK - GitLab token",
+			secret: "secret",
+			headers: map[string]string{
+				"X-Gitlab-Token": "secret",
+				"X-Gitlab-Event": "Merge Request Hook",
+			},
+			wantSource: WebhookSourceGitLab,
+			wantEvent:  "Merge Request Hook",
+			wantOK:     true,
+		},
+		{
+			name:   "KO - GitLab wrong token",
+			secret: "secret",
+			headers: map[string]string{
+				"X-Gitlab-Token": "other",
+				"X-Gitlab-Event": "Merge Request Hook",
+			},
+			wantSource: WebhookSourceGitLab,
+		},
+		{
+			name:       "KO - no authentication",
+			secret:     "secret",
+			wantSource: WebhookSourceUnknown,
+		},
+		{
+			name: "KO - no secret configured",
+			headers: map[string]string{
+				"X-Gitlab-Token": "secret",
+			},
+			wantSource: WebhookSourceUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				r := httptest.NewRequest("POST", "/webhook", strings.NewReader(body))
+				for k, v := range tt.headers {
+					r.Header.Set(k, v)
+				}
+
+				source, event, ok := verifyWebhookRequest(r, []byte(body), tt.secret)
+				assert.Equal(t, tt.wantSource, source)
+				assert.Equal(t, tt.wantEvent, event)
+				assert.Equal(t, tt.wantOK, ok)
+			},
+		)
+	}
+}
@@ -4,17 +4,24 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+
 	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/config"
 	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/controller"
 	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/schemas"
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/store"
 )
 
 const (
+	mendRenovateSourceName         string           = "mend_renovate"
 	mendRenovateStatusEndpoint     string           = "/api/status"
 	TaskTypePullMendRenovateStatus schemas.TaskType = "TaskTypePullMendRenovateStatus"
 )
@@ -86,71 +93,250 @@ type Status struct {
 	} `json:"worker"`
 }
 
-// GetStatus call the status endpoint and collect the metrics
-func (c *MendRenovateClient) GetStatus(ctx context.Context) (Status, error) {
-	var status Status
+// PulledStatus is the last status pulled from Mend Renovate, it is shared with the other processes through the store.
+type PulledStatus struct {
+	Status   Status    `json:"status"`
+	PulledAt time.Time `json:"pulledAt"`
+
+	// Latency is the time it took to pull the status
+	Latency time.Duration `json:"latency"`
+}
+
+// LoadPulledStatus returns the last status pulled from Mend Renovate by any of the processes, ok is false if there is none yet.
+func LoadPulledStatus(ctx context.Context, s store.Store) (ps PulledStatus, ok bool, err error) {
+	b, err := s.SourceStatus(ctx, mendRenovateSourceName)
+	if err != nil || b == nil {
+		return
+	}
+
+	if err = json.Unmarshal(b, &ps); err != nil {
+		return
+	}
+
+	return ps, true, nil
+}
 
+// APIError is returned when the API responds with an unexpected status code.
+type APIError struct {
+	StatusCode int
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("unexpected response status: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// statusRequiredFields are the fields of the status the metrics are computed from.
+var statusRequiredFields = []string{
+	"jobs.queueLength",
+	"jobsInProgress",
+	"scheduler.cron",
+	"scheduler.platform",
+	"worker.currentJob",
+	"webhooks",
+}
+
+// GetRawStatus calls the status endpoint and returns the response body as is.
+func (c *MendRenovateClient) GetRawStatus(ctx context.Context) ([]byte, error) {
 	url, err := url.JoinPath(c.URL, mendRenovateStatusEndpoint)
 	if err != nil {
-		return status, fmt.Errorf("error building url: %v\n", err)
+		return nil, fmt.Errorf("error building url: %v\n", err)
 	}
 	req, err := http.NewRequest("GET", url, nil)
-	req.Header.Set("Authorization", c.Token)
 	if err != nil {
-		return status, fmt.Errorf("error buulding http request: %v\n", err)
+		return nil, fmt.Errorf("error buulding http request: %v\n", err)
 	}
+	req.Header.Set("Authorization", c.Token)
 
 	req = req.WithContext(ctx)
 	client := http.DefaultClient
 	res, err := client.Do(req)
 	if err != nil {
-		return status, fmt.Errorf("error making http request: %v\n", err)
+		return nil, fmt.Errorf("error making http request: %v\n", err)
 	}
+	defer res.Body.Close()
 
-	if err := json.NewDecoder(res.Body).Decode(&status); err != nil {
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, &APIError{StatusCode: res.StatusCode}
+	}
+
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading responce body: %v\n", err)
+	}
+
+	return b, nil
+}
+
+// GetStatus call the status endpoint and collect the metrics
+func (c *MendRenovateClient) GetStatus(ctx context.Context) (Status, error) {
+	var status Status
+
+	b, err := c.GetRawStatus(ctx)
+	if err != nil {
+		return status, err
+	}
+
+	if err := json.Unmarshal(b, &status); err != nil {
 		return status, fmt.Errorf("error decoding responce body: %v\n", err)
 	}
 
 	return status, nil
 }
 
+// CheckStatusSchema returns an error if the status misses any of the fields the metrics are
+// computed from, or if any of them does not have the expected type.
+func CheckStatusSchema(b []byte) error {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return err
+	}
+
+	var missing []string
+
+	for _, path := range statusRequiredFields {
+		var (
+			value interface{} = fields
+			ok    bool
+		)
+
+		for _, key := range strings.Split(path, ".") {
+			var object map[string]interface{}
+			if object, ok = value.(map[string]interface{}); !ok {
+				break
+			}
+
+			if value, ok = object[key]; !ok {
+				break
+			}
+		}
+
+		if !ok {
+			missing = append(missing, path)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("missing fields: %s", strings.Join(missing, ", "))
+	}
+
+	return json.Unmarshal(b, &Status{})
+}
+
 // MendRenovateController is used to handle task scheduling
 type MendRenovateController struct {
 	// Controller is the main controller handling scheduling
 	Controller *controller.Controller
-	// client
-	client *MendRenovateClient
+
+	// lastPullErr holds the outcome of the last status pull made by this process
+	lastPullErr   error
+	lastPullMutex sync.RWMutex
+
+	// createdAt grants a grace period to the first pull
+	createdAt time.Time
+}
+
+func init() {
+	controller.RegisterSource(
+		func(c *controller.Controller) controller.Source {
+			return NewMendRenovateController(c)
+		},
+	)
 }
 
+// NewMendRenovateController ..
 func NewMendRenovateController(c *controller.Controller) *MendRenovateController {
 	return &MendRenovateController{
 		Controller: c,
+		createdAt:  time.Now(),
 	}
 }
 
-// Configure set up the API metrics or sdk used to fetch the data.
-func (c *MendRenovateController) Configure(ctx context.Context) {
+// client returns the API client used to fetch the data, it is built from the
+// current config in order to take reloads into account.
+func (c *MendRenovateController) client() *MendRenovateClient {
+	cfg := c.Controller.Config().Clients.MendRenovate
 
-	c.client = &MendRenovateClient{
-		URL:   c.Controller.Config.Clients.MendRenovate.URL,
-		Token: c.Controller.Config.Clients.MendRenovate.Token,
+	return &MendRenovateClient{
+		URL:   cfg.URL,
+		Token: cfg.Token,
 	}
+}
+
+// Name implements controller.Source.
+func (c *MendRenovateController) Name() string {
+	return mendRenovateSourceName
+}
 
-	c.Controller.RegisterTasks(TaskTypePullMendRenovateStatus, c.taskHandlerPullStatus)
-	c.Controller.Schedule(ctx, TaskTypePullMendRenovateStatus, config.SchedulerConfig(c.Controller.Config.Pull.Metrics))
-	c.Controller.RegisterCollector(ctx, c.NewCollectors())
+// Tasks implements controller.Source.
+func (c *MendRenovateController) Tasks() map[schemas.TaskType]interface{} {
+	return map[schemas.TaskType]interface{}{
+		TaskTypePullMendRenovateStatus: c.taskHandlerPullStatus,
+	}
+}
+
+// Schedule implements controller.Source.
+func (c *MendRenovateController) Schedule() map[schemas.TaskType]config.SchedulerConfig {
+	return map[schemas.TaskType]config.SchedulerConfig{
+		TaskTypePullMendRenovateStatus: config.SchedulerConfig(c.Controller.Config().Pull.Metrics),
+	}
+}
+
+// WebhookTasks implements controller.WebhookTrigger, the status is refreshed upon webhook events
+// as they are likely to have triggered Renovate jobs.
+func (c *MendRenovateController) WebhookTasks() []schemas.TaskType {
+	return []schemas.TaskType{TaskTypePullMendRenovateStatus}
+}
+
+// Health implements controller.Source, it reports an error if the status could not be pulled
+// successfully for more than the configured number of pull intervals. The pulls are shared
+// amongst the replicas, the last successful one is therefore looked up in the store.
+func (c *MendRenovateController) Health(ctx context.Context) error {
+	pullCfg := c.Controller.Config().Pull.Metrics
+	maxIntervals := c.Controller.Config().Server.Health.MaxPullIntervalsWithoutSuccess
+
+	if !pullCfg.Scheduled || maxIntervals <= 0 {
+		return nil
+	}
+
+	ps, ok, err := LoadPulledStatus(ctx, c.Controller.Store)
+	if err != nil {
+		return fmt.Errorf("loading the last pulled status: %w", err)
+	}
+
+	lastSuccess := c.createdAt
+	if ok && ps.PulledAt.After(lastSuccess) {
+		lastSuccess = ps.PulledAt
+	}
+
+	if since := time.Since(lastSuccess); since > time.Duration(maxIntervals*pullCfg.IntervalSeconds)*time.Second {
+		c.lastPullMutex.RLock()
+		defer c.lastPullMutex.RUnlock()
+
+		return fmt.Errorf("no successful status pull for %s, last local error: %v", since.Round(time.Second), c.lastPullErr)
+	}
+
+	return nil
 }
 
 // taskHandlerPullStatus scrape men renovate metrics endpoint and store the relevant metrics
 func (c *MendRenovateController) taskHandlerPullStatus(ctx context.Context) (err error) {
 	defer c.Controller.UnqueueTask(ctx, TaskTypePullMendRenovateStatus, "_")
-	defer c.Controller.TaskController.MonitorLastTaskScheduling(TaskTypePullMendRenovateStatus)
+	defer c.Controller.MonitorLastTaskScheduling(ctx, TaskTypePullMendRenovateStatus)
+
+	start := time.Now()
+	status, err := c.client().GetStatus(ctx)
+	latency := time.Since(start)
+
+	c.lastPullMutex.Lock()
+	c.lastPullErr = err
+	c.lastPullMutex.Unlock()
 
-	status, err := c.client.GetStatus(ctx)
 	if err != nil {
 		return
 	}
 
+	c.storePulledStatus(ctx, status, latency)
+
 	controller.StoreSetMetric(
 		ctx, c.Controller.Store, schemas.Metric{
 			Kind:   MetricKindRenovateJobsQueueLength,
@@ -165,10 +351,24 @@ func (c *MendRenovateController) taskHandlerPullStatus(ctx context.Context) (err
 	return
 }
 
-// NewCollectors returns a new collector for resource exposed for this controller.
-func (c *MendRenovateController) NewCollectors() controller.RegistryCollectors {
+// storePulledStatus shares the status with the other processes, for monitoring purposes.
+func (c *MendRenovateController) storePulledStatus(ctx context.Context, status Status, latency time.Duration) {
+	b, err := json.Marshal(PulledStatus{Status: status, PulledAt: time.Now(), Latency: latency})
+	if err == nil {
+		err = c.Controller.Store.SetSourceStatus(ctx, c.Name(), b)
+	}
+
+	if err != nil {
+		log.WithContext(ctx).
+			WithError(err).
+			Warn("storing the pulled status")
+	}
+}
+
+// Collectors implements controller.Source, it returns the collectors for resource exposed for this controller.
+func (c *MendRenovateController) Collectors() controller.RegistryCollectors {
 	return controller.RegistryCollectors{
-		MetricKindRenovateJobsQueueLength: prometheus.NewGaugeVec(
+		MetricKindRenovateJobsQueueLength: controller.NewGaugeVecCollector(
 			prometheus.GaugeOpts{
 				Name: "mre_renovate_jobs_queue_length",
 				Help: "Number of Jobs in Renovate Queue",
@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/config"
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/controller"
+)
+
+func TestMendRenovateClientGetStatus(t *testing.T) {
+	b, err := os.ReadFile("testdata/mend-renovate-status.json")
+	require.NoError(t, err)
+
+	var testdata struct {
+		Status json.RawMessage `json:"status"`
+	}
+	require.NoError(t, json.Unmarshal(b, &testdata))
+
+	renovate := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"unauthorized"}`))
+
+			return
+		}
+
+		_, _ = w.Write(testdata.Status)
+	}))
+	defer renovate.Close()
+
+	client := &MendRenovateClient{URL: renovate.URL, Token: "secret"}
+
+	status, err := client.GetStatus(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "org/repo", status.Jobs.LastJob.Repository)
+
+	// The error responses used to be decoded as an empty status
+	client.Token = "foo"
+	_, err = client.GetStatus(context.Background())
+
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+	assert.EqualError(t, err, "unexpected response status: 401 Unauthorized")
+}
+
+func TestCheckStatusSchema(t *testing.T) {
+	b, err := os.ReadFile("testdata/mend-renovate-status.json")
+	require.NoError(t, err)
+
+	var testdata struct {
+		Status json.RawMessage `json:"status"`
+	}
+	require.NoError(t, json.Unmarshal(b, &testdata))
+
+	assert.NoError(t, CheckStatusSchema(testdata.Status))
+
+	assert.EqualError(t,
+		CheckStatusSchema([]byte(`{"jobs":{},"jobsInProgress":[],"scheduler":{"cron":"* * * * *"},"webhooks":{}}`)),
+		"missing fields: jobs.queueLength, scheduler.platform, worker.currentJob",
+	)
+
+	assert.EqualError(t,
+		CheckStatusSchema([]byte(`{"error":"unauthorized"}`)),
+		"missing fields: jobs.queueLength, jobsInProgress, scheduler.cron, scheduler.platform, worker.currentJob, webhooks",
+	)
+
+	assert.Error(t, CheckStatusSchema([]byte(`{"jobs":{"queueLength":"many"},"jobsInProgress":[],"scheduler":{"cron":"","platform":""},"worker":{"currentJob":{}},"webhooks":{}}`)))
+	assert.Error(t, CheckStatusSchema([]byte(`<html></html>`)))
+}
+
+func TestMendRenovateControllerHealth(t *testing.T) {
+	ctx := context.Background()
+
+	cfg := config.New()
+	cfg.Pull.Metrics.IntervalSeconds = 1
+	cfg.Server.Health.MaxPullIntervalsWithoutSuccess = 2
+
+	c, err := controller.New(ctx, cfg, "test")
+	require.NoError(t, err)
+
+	mrc := NewMendRenovateController(&c)
+
+	// The first pull is granted a grace period
+	assert.NoError(t, mrc.Health(ctx))
+
+	mrc.createdAt = time.Now().Add(-time.Minute)
+	assert.ErrorContains(t, mrc.Health(ctx), "no successful status pull for 1m0s")
+
+	// The pulls made by the other replicas are taken into account through the store
+	mrc.storePulledStatus(ctx, Status{}, time.Second)
+	assert.NoError(t, mrc.Health(ctx))
+}
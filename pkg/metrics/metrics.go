@@ -8,4 +8,19 @@ import (
 const (
 	// MetricKindRenovateJobsQueueLength ..
 	MetricKindRenovateJobsQueueLength schemas.MetricKind = iota
+
+	// MetricKindRenovatePullRequestsOpened ..
+	MetricKindRenovatePullRequestsOpened
+
+	// MetricKindRenovatePullRequestsMerged ..
+	MetricKindRenovatePullRequestsMerged
+
+	// MetricKindRenovatePullRequestsClosedUnmerged ..
+	MetricKindRenovatePullRequestsClosedUnmerged
+
+	// MetricKindRenovatePullRequestTimeToMerge ..
+	MetricKindRenovatePullRequestTimeToMerge
+
+	// MetricKindRenovatePullRequestsReopened ..
+	MetricKindRenovatePullRequestsReopened
 )
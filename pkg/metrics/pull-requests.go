@@ -0,0 +1,351 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/config"
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/controller"
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/schemas"
+)
+
+// List of the pull request actions we keep track of. The reopened pull requests
+// are counted on their own, as they have already been counted when opened.
+const (
+	pullRequestActionOpened   = "opened"
+	pullRequestActionReopened = "reopened"
+	pullRequestActionMerged   = "merged"
+	pullRequestActionClosed   = "closed"
+)
+
+// List of the update types of the pull requests.
+const (
+	UpdateTypeMajor   = "major"
+	UpdateTypeMinor   = "minor"
+	UpdateTypePatch   = "patch"
+	UpdateTypeUnknown = "unknown"
+)
+
+// timeToMergeBuckets range from a minute to a month, as automerged pull requests
+// get merged within minutes whilst the others can wait for weeks.
+var timeToMergeBuckets = []float64{60, 300, 900, 3600, 4 * 3600, 12 * 3600, 86400, 3 * 86400, 7 * 86400, 14 * 86400, 30 * 86400}
+
+// updateTypeLabelRegexp matches the labels holding the update type, optionally prefixed, eg: major, update:major or type/major.
+var updateTypeLabelRegexp = regexp.MustCompile(`(?i)^(?:[\w-]+\s*[:/]\s*)?(major|minor|patch)$`)
+
+// updateTypeTitleRegexp matches the update type Renovate can append to the titles, eg: update foo to v5 (major).
+var updateTypeTitleRegexp = regexp.MustCompile(`(?i)\((major|minor|patch)\)$`)
+
+// pullRequest is the platform agnostic representation of a pull request event.
+type pullRequest struct {
+	Action     string
+	Repository string
+	Author     string
+	Branch     string
+	Title      string
+	Labels     []string
+	CreatedAt  time.Time
+	MergedAt   time.Time
+}
+
+// githubPullRequestEvent is the payload of the GitHub pull_request events.
+type githubPullRequestEvent struct {
+	Action      string `json:"action"`
+	PullRequest struct {
+		Title string `json:"title"`
+		User  struct {
+			Login string `json:"login"`
+		} `json:"user"`
+		Head struct {
+			Ref string `json:"ref"`
+		} `json:"head"`
+		Labels []struct {
+			Name string `json:"name"`
+		} `json:"labels"`
+		Merged    bool      `json:"merged"`
+		CreatedAt time.Time `json:"created_at"`
+		MergedAt  time.Time `json:"merged_at"`
+	} `json:"pull_request"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// gitlabMergeRequestEvent is the payload of the GitLab merge request events.
+type gitlabMergeRequestEvent struct {
+	User struct {
+		Username string `json:"username"`
+	} `json:"user"`
+	Project struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+	} `json:"project"`
+	ObjectAttributes struct {
+		Action       string `json:"action"`
+		Title        string `json:"title"`
+		SourceBranch string `json:"source_branch"`
+		CreatedAt    string `json:"created_at"`
+	} `json:"object_attributes"`
+	Labels []struct {
+		Title string `json:"title"`
+	} `json:"labels"`
+}
+
+// PullRequestsController keeps track of the pull requests opened by Renovate using the platforms webhooks.
+type PullRequestsController struct {
+	// Controller is the main controller handling scheduling
+	Controller *controller.Controller
+}
+
+func init() {
+	controller.RegisterSource(
+		func(c *controller.Controller) controller.Source {
+			return NewPullRequestsController(c)
+		},
+	)
+}
+
+// NewPullRequestsController ..
+func NewPullRequestsController(c *controller.Controller) *PullRequestsController {
+	return &PullRequestsController{
+		Controller: c,
+	}
+}
+
+// Name implements controller.Source.
+func (c *PullRequestsController) Name() string {
+	return "renovate_pull_requests"
+}
+
+// Tasks implements controller.Source, the data is pushed through webhooks.
+func (c *PullRequestsController) Tasks() map[schemas.TaskType]interface{} {
+	return nil
+}
+
+// Schedule implements controller.Source, the data is pushed through webhooks.
+func (c *PullRequestsController) Schedule() map[schemas.TaskType]config.SchedulerConfig {
+	return nil
+}
+
+// Health implements controller.Source.
+func (c *PullRequestsController) Health(_ context.Context) error {
+	return nil
+}
+
+// HandleWebhook implements controller.WebhookConsumer, it counts the activity of the Renovate pull requests.
+func (c *PullRequestsController) HandleWebhook(ctx context.Context, e controller.WebhookEvent) error {
+	pr, ok, err := parsePullRequestEvent(e)
+	if err != nil || !ok {
+		return err
+	}
+
+	if !isRenovatePullRequest(pr, c.Controller.Config().Renovate) {
+		return nil
+	}
+
+	labels := prometheus.Labels{
+		"repository":  pr.Repository,
+		"update_type": pullRequestUpdateType(pr),
+	}
+
+	switch pr.Action {
+	case pullRequestActionOpened:
+		controller.StoreIncMetric(ctx, c.Controller.Store, schemas.Metric{
+			Kind:   MetricKindRenovatePullRequestsOpened,
+			Labels: labels,
+			Value:  1,
+		})
+	case pullRequestActionReopened:
+		controller.StoreIncMetric(ctx, c.Controller.Store, schemas.Metric{
+			Kind:   MetricKindRenovatePullRequestsReopened,
+			Labels: labels,
+			Value:  1,
+		})
+	case pullRequestActionMerged:
+		controller.StoreIncMetric(ctx, c.Controller.Store, schemas.Metric{
+			Kind:   MetricKindRenovatePullRequestsMerged,
+			Labels: labels,
+			Value:  1,
+		})
+
+		if !pr.CreatedAt.IsZero() {
+			controller.StoreObserveHistogram(
+				ctx,
+				c.Controller.Store,
+				MetricKindRenovatePullRequestTimeToMerge,
+				prometheus.Labels{"update_type": labels["update_type"]},
+				timeToMergeBuckets,
+				pr.MergedAt.Sub(pr.CreatedAt).Seconds(),
+			)
+		}
+	case pullRequestActionClosed:
+		controller.StoreIncMetric(ctx, c.Controller.Store, schemas.Metric{
+			Kind:   MetricKindRenovatePullRequestsClosedUnmerged,
+			Labels: labels,
+			Value:  1,
+		})
+	}
+
+	return nil
+}
+
+// Collectors implements controller.Source, it returns the collectors for resource exposed for this controller.
+func (c *PullRequestsController) Collectors() controller.RegistryCollectors {
+	return controller.RegistryCollectors{
+		MetricKindRenovatePullRequestsOpened: controller.NewCounterVecCollector(
+			prometheus.CounterOpts{
+				Name: "mre_renovate_pull_requests_opened_total",
+				Help: "Number of pull requests opened by Renovate",
+			},
+			[]string{"repository", "update_type"},
+		),
+		MetricKindRenovatePullRequestsReopened: controller.NewCounterVecCollector(
+			prometheus.CounterOpts{
+				Name: "mre_renovate_pull_requests_reopened_total",
+				Help: "Number of pull requests opened by Renovate which got reopened after being closed",
+			},
+			[]string{"repository", "update_type"},
+		),
+		MetricKindRenovatePullRequestsMerged: controller.NewCounterVecCollector(
+			prometheus.CounterOpts{
+				Name: "mre_renovate_pull_requests_merged_total",
+				Help: "Number of pull requests opened by Renovate which got merged",
+			},
+			[]string{"repository", "update_type"},
+		),
+		MetricKindRenovatePullRequestsClosedUnmerged: controller.NewCounterVecCollector(
+			prometheus.CounterOpts{
+				Name: "mre_renovate_pull_requests_closed_unmerged_total",
+				Help: "Number of pull requests opened by Renovate which got closed without being merged",
+			},
+			[]string{"repository", "update_type"},
+		),
+		MetricKindRenovatePullRequestTimeToMerge: controller.NewHistogramVecCollector(
+			prometheus.HistogramOpts{
+				Name:    "mre_renovate_pull_request_time_to_merge_seconds",
+				Help:    "Time between the opening and the merge of the pull requests opened by Renovate",
+				Buckets: timeToMergeBuckets,
+			},
+			[]string{"update_type"},
+		),
+	}
+}
+
+// parsePullRequestEvent extracts the pull request out of the webhook event, ok is false
+// for events which are not about pull requests or actions we do not keep track of.
+func parsePullRequestEvent(e controller.WebhookEvent) (pr pullRequest, ok bool, err error) {
+	switch {
+	case e.Source == controller.WebhookSourceGitHub && e.Event == "pull_request":
+		var payload githubPullRequestEvent
+		if err = json.Unmarshal(e.Body, &payload); err != nil {
+			return
+		}
+
+		switch payload.Action {
+		case "opened":
+			pr.Action = pullRequestActionOpened
+		case "reopened":
+			pr.Action = pullRequestActionReopened
+		case "closed":
+			pr.Action = pullRequestActionClosed
+			if payload.PullRequest.Merged {
+				pr.Action = pullRequestActionMerged
+			}
+		default:
+			return
+		}
+
+		pr.Repository = payload.Repository.FullName
+		pr.Author = payload.PullRequest.User.Login
+		pr.Branch = payload.PullRequest.Head.Ref
+		pr.Title = payload.PullRequest.Title
+		pr.CreatedAt = payload.PullRequest.CreatedAt
+		pr.MergedAt = payload.PullRequest.MergedAt
+
+		for _, l := range payload.PullRequest.Labels {
+			pr.Labels = append(pr.Labels, l.Name)
+		}
+
+	case e.Source == controller.WebhookSourceGitLab && e.Event == "Merge Request Hook":
+		var payload gitlabMergeRequestEvent
+		if err = json.Unmarshal(e.Body, &payload); err != nil {
+			return
+		}
+
+		switch payload.ObjectAttributes.Action {
+		case "open":
+			pr.Action = pullRequestActionOpened
+		case "reopen":
+			pr.Action = pullRequestActionReopened
+		case "merge":
+			pr.Action = pullRequestActionMerged
+		case "close":
+			pr.Action = pullRequestActionClosed
+		default:
+			return
+		}
+
+		pr.Repository = payload.Project.PathWithNamespace
+		// GitLab only provides the user triggering the event, which is the author when opening
+		pr.Author = payload.User.Username
+		pr.Branch = payload.ObjectAttributes.SourceBranch
+		pr.Title = payload.ObjectAttributes.Title
+		pr.CreatedAt = parseGitlabTime(payload.ObjectAttributes.CreatedAt)
+
+		for _, l := range payload.Labels {
+			pr.Labels = append(pr.Labels, l.Title)
+		}
+
+	default:
+		return
+	}
+
+	// The merge time is not provided by all the platforms, the event is sent as it happens
+	if pr.Action == pullRequestActionMerged && pr.MergedAt.IsZero() {
+		pr.MergedAt = time.Now()
+	}
+
+	return pr, true, nil
+}
+
+// parseGitlabTime parses the timestamps of the GitLab webhooks, whose format depends on the event and version.
+func parseGitlabTime(s string) time.Time {
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05 MST", "2006-01-02 15:04:05 -0700"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// isRenovatePullRequest tells whether the pull request has been opened by Renovate.
+func isRenovatePullRequest(pr pullRequest, cfg config.Renovate) bool {
+	if cfg.BotUsername != "" && pr.Author == cfg.BotUsername {
+		return true
+	}
+
+	return cfg.BranchPrefix != "" && strings.HasPrefix(pr.Branch, cfg.BranchPrefix)
+}
+
+// pullRequestUpdateType infers the update type of the pull request, the labels
+// are looked up first as they are more reliable than the title. Only the labels
+// and the title suffix dedicated to the update type are considered, as the
+// names of the dependencies can contain the words as well, eg: patch-package.
+func pullRequestUpdateType(pr pullRequest) string {
+	for _, l := range pr.Labels {
+		if m := updateTypeLabelRegexp.FindStringSubmatch(strings.TrimSpace(l)); m != nil {
+			return strings.ToLower(m[1])
+		}
+	}
+
+	if m := updateTypeTitleRegexp.FindStringSubmatch(strings.TrimSpace(pr.Title)); m != nil {
+		return strings.ToLower(m[1])
+	}
+
+	return UpdateTypeUnknown
+}
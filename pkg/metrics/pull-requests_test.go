@@ -0,0 +1,220 @@
+package metrics
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/config"
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/controller"
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/schemas"
+)
+
+func TestParsePullRequestEvent(t *testing.T) {
+	github, err := os.ReadFile("testdata/github-pull-request-merged.json")
+	require.NoError(t, err)
+
+	gitlab, err := os.ReadFile("testdata/gitlab-merge-request-open.json")
+	require.NoError(t, err)
+
+	pr, ok, err := parsePullRequestEvent(controller.WebhookEvent{
+		Source: controller.WebhookSourceGitHub,
+		Event:  "pull_request",
+		Body:   github,
+	})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, pullRequest{
+		Action:     pullRequestActionMerged,
+		Repository: "xnok/mend-renovate-ce-ee-exporter",
+		Author:     "renovate[bot]",
+		Branch:     "renovate/golang-1.x",
+		Title:      "Update dependency golang to v1.21.5",
+		Labels:     []string{"dependencies", "patch"},
+		CreatedAt:  time.Date(2023, 12, 1, 10, 0, 0, 0, time.UTC),
+		MergedAt:   time.Date(2023, 12, 1, 11, 0, 0, 0, time.UTC),
+	}, pr)
+
+	pr, ok, err = parsePullRequestEvent(controller.WebhookEvent{
+		Source: controller.WebhookSourceGitLab,
+		Event:  "Merge Request Hook",
+		Body:   gitlab,
+	})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, pullRequestActionOpened, pr.Action)
+	assert.Equal(t, "xnok/infrastructure", pr.Repository)
+	assert.Equal(t, time.Date(2023, 12, 1, 10, 0, 0, 0, time.UTC), pr.CreatedAt)
+
+	// Other events are ignored
+	_, ok, err = parsePullRequestEvent(controller.WebhookEvent{
+		Source: controller.WebhookSourceGitHub,
+		Event:  "push",
+		Body:   github,
+	})
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, _, err = parsePullRequestEvent(controller.WebhookEvent{
+		Source: controller.WebhookSourceGitHub,
+		Event:  "pull_request",
+		Body:   []byte("{"),
+	})
+	assert.Error(t, err)
+}
+
+func TestIsRenovatePullRequest(t *testing.T) {
+	cfg := config.Renovate{BotUsername: "renovate[bot]", BranchPrefix: "renovate/"}
+
+	assert.True(t, isRenovatePullRequest(pullRequest{Author: "renovate[bot]", Branch: "feature"}, cfg))
+	assert.True(t, isRenovatePullRequest(pullRequest{Author: "someone", Branch: "renovate/foo"}, cfg))
+	assert.False(t, isRenovatePullRequest(pullRequest{Author: "someone", Branch: "feature"}, cfg))
+	assert.False(t, isRenovatePullRequest(pullRequest{Author: "someone", Branch: "feature"}, config.Renovate{}))
+}
+
+func TestPullRequestUpdateType(t *testing.T) {
+	tests := []struct {
+		name string
+		pr   pullRequest
+		want string
+	}{
+		{
+			name: "label",
+			pr:   pullRequest{Title: "Update dependency foo to v2", Labels: []string{"dependencies", "update:Major"}},
+			want: UpdateTypeMajor,
+		},
+		{
+			name: "labels take precedence over the title",
+			pr:   pullRequest{Title: "Update foo (patch)", Labels: []string{"minor"}},
+			want: UpdateTypeMinor,
+		},
+		{
+			name: "title",
+			pr:   pullRequest{Title: "chore(deps): update terraform aws to v5 (major)"},
+			want: UpdateTypeMajor,
+		},
+		{
+			name: "unknown",
+			pr:   pullRequest{Title: "Update dependency foo to v2", Labels: []string{"dependencies"}},
+			want: UpdateTypeUnknown,
+		},
+		{
+			name: "dependency named after an update type",
+			pr:   pullRequest{Title: "Update patch-package to v8"},
+			want: UpdateTypeUnknown,
+		},
+		{
+			name: "prefixed dependency named after an update type",
+			pr:   pullRequest{Title: "chore(deps): update dependency minor-lib to v2"},
+			want: UpdateTypeUnknown,
+		},
+		{
+			name: "update type within the title",
+			pr:   pullRequest{Title: "Update major version of foo to v3.1.0"},
+			want: UpdateTypeUnknown,
+		},
+		{
+			name: "label named after an update type",
+			pr:   pullRequest{Title: "Update dependency foo to v2", Labels: []string{"patch-package", "major incident"}},
+			want: UpdateTypeUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				assert.Equal(t, tt.want, pullRequestUpdateType(tt.pr))
+			},
+		)
+	}
+}
+
+func TestPullRequestsControllerHandleWebhook(t *testing.T) {
+	ctx := context.Background()
+
+	c, err := controller.New(ctx, config.New(), "test")
+	require.NoError(t, err)
+
+	body, err := os.ReadFile("testdata/github-pull-request-merged.json")
+	require.NoError(t, err)
+
+	prc := NewPullRequestsController(&c)
+	e := controller.WebhookEvent{
+		Source: controller.WebhookSourceGitHub,
+		Event:  "pull_request",
+		Body:   body,
+	}
+
+	// Redelivered events are counted, as the platforms do not provide a way to tell them apart
+	require.NoError(t, prc.HandleWebhook(ctx, e))
+	require.NoError(t, prc.HandleWebhook(ctx, e))
+
+	merged := schemas.Metric{
+		Kind: MetricKindRenovatePullRequestsMerged,
+		Labels: prometheus.Labels{
+			"repository":  "xnok/mend-renovate-ce-ee-exporter",
+			"update_type": UpdateTypePatch,
+		},
+	}
+	require.NoError(t, c.Store.GetMetric(ctx, &merged))
+	assert.Equal(t, float64(2), merged.Value)
+
+	timeToMergeSum := schemas.Metric{
+		Kind: MetricKindRenovatePullRequestTimeToMerge,
+		Labels: prometheus.Labels{
+			"update_type": UpdateTypePatch,
+			"le":          "sum",
+		},
+	}
+	require.NoError(t, c.Store.GetMetric(ctx, &timeToMergeSum))
+	assert.Equal(t, float64(2*3600), timeToMergeSum.Value)
+
+	opened := schemas.Metric{
+		Kind: MetricKindRenovatePullRequestsOpened,
+		Labels: prometheus.Labels{
+			"repository":  "xnok/mend-renovate-ce-ee-exporter",
+			"update_type": UpdateTypePatch,
+		},
+	}
+	exists, err := c.Store.MetricExists(ctx, opened.Key())
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestPullRequestsControllerHandleWebhookReopened(t *testing.T) {
+	ctx := context.Background()
+
+	c, err := controller.New(ctx, config.New(), "test")
+	require.NoError(t, err)
+
+	prc := NewPullRequestsController(&c)
+	require.NoError(t, prc.HandleWebhook(ctx, controller.WebhookEvent{
+		Source: controller.WebhookSourceGitHub,
+		Event:  "pull_request",
+		Body: []byte(`{
+			"action": "reopened",
+			"pull_request": {"title": "Update foo to v2 (major)", "user": {"login": "renovate[bot]"}, "head": {"ref": "renovate/foo-2.x"}},
+			"repository": {"full_name": "xnok/foo"}
+		}`),
+	}))
+
+	labels := prometheus.Labels{
+		"repository":  "xnok/foo",
+		"update_type": UpdateTypeMajor,
+	}
+
+	// The pull request has already been counted when opened
+	reopened := schemas.Metric{Kind: MetricKindRenovatePullRequestsReopened, Labels: labels}
+	require.NoError(t, c.Store.GetMetric(ctx, &reopened))
+	assert.Equal(t, float64(1), reopened.Value)
+
+	opened := schemas.Metric{Kind: MetricKindRenovatePullRequestsOpened, Labels: labels}
+	exists, err := c.Store.MetricExists(ctx, opened.Key())
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
@@ -6,7 +6,6 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 
 	pb "github.com/xnok/mend-renovate-ce-ee-exporter/pkg/monitor/protobuf"
 )
@@ -17,13 +16,24 @@ type Client struct {
 }
 
 // NewClient ..
-func NewClient(ctx context.Context, endpoint *url.URL) *Client {
+func NewClient(ctx context.Context, endpoint *url.URL, opts Options) *Client {
 	log.WithField("endpoint", endpoint.String()).Debug("establishing gRPC connection to the server..")
 
+	dialOpts, err := opts.dialOptions(endpoint.Scheme)
+	if err != nil {
+		log.WithField("endpoint", endpoint.String()).WithError(err).Fatal("configuring the connection to the server")
+	}
+
+	// gRPC resolves unix sockets from the URL, other addresses are dialed as is
+	target := endpoint.String()
+	if endpoint.Scheme != "unix" {
+		target = endpoint.Host
+	}
+
 	conn, err := grpc.DialContext(
 		ctx,
-		endpoint.String(),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		target,
+		dialOpts...,
 	)
 	if err != nil {
 		log.WithField("endpoint", endpoint.String()).WithField("error", err).Fatal("could not connect to the server")
@@ -0,0 +1,119 @@
+package monitor
+
+import (
+	"context"
+	"time"
+
+	"github.com/heptiolabs/healthcheck"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/controller"
+)
+
+// Names of the health services aggregating the checks, on top of the
+// overall one ("") and of the individual checks named after themselves.
+const (
+	HealthServiceReadiness = "readiness"
+	HealthServiceLiveness  = "liveness"
+)
+
+// healthWatchInterval is the interval at which the watched health checks are evaluated.
+const healthWatchInterval = 5 * time.Second
+
+// healthServer implements the grpc.health.v1 service on top of the health checks of the controller.
+type healthServer struct {
+	healthpb.UnimplementedHealthServer
+
+	controller *controller.Controller
+}
+
+// Check evaluates the checks of the service.
+func (h *healthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	checks, ok := h.checks(ctx, req.GetService())
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown service '%s'", req.GetService())
+	}
+
+	return &healthpb.HealthCheckResponse{Status: servingStatus(checks)}, nil
+}
+
+// Watch streams the status of the service whenever it changes, until the client goes away.
+func (h *healthServer) Watch(req *healthpb.HealthCheckRequest, hs healthpb.Health_WatchServer) error {
+	ctx := hs.Context()
+
+	ticker := time.NewTicker(healthWatchInterval)
+	defer ticker.Stop()
+
+	var last healthpb.HealthCheckResponse_ServingStatus = -1
+
+	for {
+		// Unknown services are reported as such, they may show up later on
+		current := healthpb.HealthCheckResponse_SERVICE_UNKNOWN
+		if checks, ok := h.checks(ctx, req.GetService()); ok {
+			current = servingStatus(checks)
+		}
+
+		if current != last {
+			if err := hs.Send(&healthpb.HealthCheckResponse{Status: current}); err != nil {
+				return err
+			}
+
+			last = current
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// checks returns the checks behind the service, ok is false if there is no such service.
+func (h *healthServer) checks(ctx context.Context, service string) (checks []healthcheck.Check, ok bool) {
+	readiness, liveness := h.controller.HealthChecks(ctx)
+
+	switch service {
+	case "":
+		for _, check := range readiness {
+			checks = append(checks, check)
+		}
+
+		for _, check := range liveness {
+			checks = append(checks, check)
+		}
+	case HealthServiceReadiness:
+		for _, check := range readiness {
+			checks = append(checks, check)
+		}
+	case HealthServiceLiveness:
+		for _, check := range liveness {
+			checks = append(checks, check)
+		}
+	default:
+		check, found := readiness[service]
+		if !found {
+			check, found = liveness[service]
+		}
+
+		if !found {
+			return nil, false
+		}
+
+		checks = append(checks, check)
+	}
+
+	return checks, true
+}
+
+func servingStatus(checks []healthcheck.Check) healthpb.HealthCheckResponse_ServingStatus {
+	for _, check := range checks {
+		if check() != nil {
+			return healthpb.HealthCheckResponse_NOT_SERVING
+		}
+	}
+
+	return healthpb.HealthCheckResponse_SERVING
+}
@@ -0,0 +1,116 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/config"
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/controller"
+)
+
+func TestHealthCheck(t *testing.T) {
+	ctx := context.Background()
+
+	c, err := controller.New(ctx, config.New(), "test")
+	require.NoError(t, err)
+
+	// The health service does not require the token
+	client := healthpb.NewHealthClient(newTestConn(t, &c, Options{Token: "s3cr3t"}, Options{}))
+
+	for _, service := range []string{"", HealthServiceReadiness, HealthServiceLiveness, controller.HealthCheckTaskFailures} {
+		res, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		require.NoError(t, err, service)
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, res.GetStatus(), service)
+	}
+
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{Service: "foo"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// Consecutive task failures make the exporter not ready
+	c.RegisterTasks("fail", func() error { return errors.New("failed") })
+	task := c.TaskController.TaskMap.Get("fail")
+
+	for i := 0; i < c.TaskController.Queue.Options().PauseErrorsThreshold; i++ {
+		_ = task.HandleJob(ctx, task.NewJob(time.Now().UnixNano()))
+	}
+
+	for service, expected := range map[string]healthpb.HealthCheckResponse_ServingStatus{
+		"":                                 healthpb.HealthCheckResponse_NOT_SERVING,
+		HealthServiceReadiness:             healthpb.HealthCheckResponse_NOT_SERVING,
+		HealthServiceLiveness:              healthpb.HealthCheckResponse_SERVING,
+		controller.HealthCheckTaskFailures: healthpb.HealthCheckResponse_NOT_SERVING,
+	} {
+		res, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		require.NoError(t, err, service)
+		assert.Equal(t, expected, res.GetStatus(), service)
+	}
+}
+
+func TestHealthWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c, err := controller.New(ctx, config.New(), "test")
+	require.NoError(t, err)
+
+	client := healthpb.NewHealthClient(newTestConn(t, &c, Options{}, Options{}))
+
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{Service: HealthServiceLiveness})
+	require.NoError(t, err)
+
+	res, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, res.GetStatus())
+
+	stream, err = client.Watch(ctx, &healthpb.HealthCheckRequest{Service: "foo"})
+	require.NoError(t, err)
+
+	res, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVICE_UNKNOWN, res.GetStatus())
+}
+
+func TestReflection(t *testing.T) {
+	ctx := context.Background()
+
+	c, err := controller.New(ctx, config.New(), "test")
+	require.NoError(t, err)
+
+	listServices := func(opts Options) ([]string, error) {
+		stream, err := reflectionpb.NewServerReflectionClient(newTestConn(t, &c, opts, Options{})).ServerReflectionInfo(ctx)
+		require.NoError(t, err)
+
+		require.NoError(t, stream.Send(&reflectionpb.ServerReflectionRequest{
+			MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+		}))
+
+		res, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+
+		services := []string{}
+		for _, s := range res.GetListServicesResponse().GetService() {
+			services = append(services, s.GetName())
+		}
+
+		return services, nil
+	}
+
+	_, err = listServices(Options{})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	services, err := listServices(Options{Reflection: true})
+	require.NoError(t, err)
+	assert.Contains(t, services, "monitor.Monitor")
+	assert.Contains(t, services, healthpb.Health_ServiceDesc.ServiceName)
+}
@@ -25,6 +25,16 @@ const _ = grpc.SupportPackageIsVersion7
 type MonitorClient interface {
 	GetConfig(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Config, error)
 	GetTelemetry(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Monitor_GetTelemetryClient, error)
+	GetRenovateStatus(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Monitor_GetRenovateStatusClient, error)
+	ListMetrics(ctx context.Context, in *ListMetricsRequest, opts ...grpc.CallOption) (*Metrics, error)
+	GetCluster(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Monitor_GetClusterClient, error)
+	ListTasks(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Tasks, error)
+	TriggerTask(ctx context.Context, in *TaskRequest, opts ...grpc.CallOption) (*TriggerTaskResponse, error)
+	PauseTaskScheduling(ctx context.Context, in *TaskRequest, opts ...grpc.CallOption) (*Empty, error)
+	ResumeTaskScheduling(ctx context.Context, in *TaskRequest, opts ...grpc.CallOption) (*Empty, error)
+	PurgeTaskQueue(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ReleasedTaskLocks, error)
+	DeleteMetric(ctx context.Context, in *DeleteMetricRequest, opts ...grpc.CallOption) (*Empty, error)
+	ReleaseStaleTaskLocks(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ReleasedTaskLocks, error)
 }
 
 type monitorClient struct {
@@ -76,12 +86,158 @@ func (x *monitorGetTelemetryClient) Recv() (*Telemetry, error) {
 	return m, nil
 }
 
+func (c *monitorClient) GetRenovateStatus(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Monitor_GetRenovateStatusClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Monitor_ServiceDesc.Streams[1], "/monitor.Monitor/GetRenovateStatus", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &monitorGetRenovateStatusClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Monitor_GetRenovateStatusClient interface {
+	Recv() (*RenovateStatus, error)
+	grpc.ClientStream
+}
+
+type monitorGetRenovateStatusClient struct {
+	grpc.ClientStream
+}
+
+func (x *monitorGetRenovateStatusClient) Recv() (*RenovateStatus, error) {
+	m := new(RenovateStatus)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *monitorClient) ListMetrics(ctx context.Context, in *ListMetricsRequest, opts ...grpc.CallOption) (*Metrics, error) {
+	out := new(Metrics)
+	err := c.cc.Invoke(ctx, "/monitor.Monitor/ListMetrics", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) GetCluster(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Monitor_GetClusterClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Monitor_ServiceDesc.Streams[2], "/monitor.Monitor/GetCluster", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &monitorGetClusterClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Monitor_GetClusterClient interface {
+	Recv() (*Cluster, error)
+	grpc.ClientStream
+}
+
+type monitorGetClusterClient struct {
+	grpc.ClientStream
+}
+
+func (x *monitorGetClusterClient) Recv() (*Cluster, error) {
+	m := new(Cluster)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *monitorClient) ListTasks(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Tasks, error) {
+	out := new(Tasks)
+	err := c.cc.Invoke(ctx, "/monitor.Monitor/ListTasks", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) TriggerTask(ctx context.Context, in *TaskRequest, opts ...grpc.CallOption) (*TriggerTaskResponse, error) {
+	out := new(TriggerTaskResponse)
+	err := c.cc.Invoke(ctx, "/monitor.Monitor/TriggerTask", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) PauseTaskScheduling(ctx context.Context, in *TaskRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/monitor.Monitor/PauseTaskScheduling", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) ResumeTaskScheduling(ctx context.Context, in *TaskRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/monitor.Monitor/ResumeTaskScheduling", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) PurgeTaskQueue(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ReleasedTaskLocks, error) {
+	out := new(ReleasedTaskLocks)
+	err := c.cc.Invoke(ctx, "/monitor.Monitor/PurgeTaskQueue", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) DeleteMetric(ctx context.Context, in *DeleteMetricRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/monitor.Monitor/DeleteMetric", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) ReleaseStaleTaskLocks(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ReleasedTaskLocks, error) {
+	out := new(ReleasedTaskLocks)
+	err := c.cc.Invoke(ctx, "/monitor.Monitor/ReleaseStaleTaskLocks", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // MonitorServer is the server API for Monitor service.
 // All implementations must embed UnimplementedMonitorServer
 // for forward compatibility
 type MonitorServer interface {
 	GetConfig(context.Context, *Empty) (*Config, error)
 	GetTelemetry(*Empty, Monitor_GetTelemetryServer) error
+	GetRenovateStatus(*Empty, Monitor_GetRenovateStatusServer) error
+	ListMetrics(context.Context, *ListMetricsRequest) (*Metrics, error)
+	GetCluster(*Empty, Monitor_GetClusterServer) error
+	ListTasks(context.Context, *Empty) (*Tasks, error)
+	TriggerTask(context.Context, *TaskRequest) (*TriggerTaskResponse, error)
+	PauseTaskScheduling(context.Context, *TaskRequest) (*Empty, error)
+	ResumeTaskScheduling(context.Context, *TaskRequest) (*Empty, error)
+	PurgeTaskQueue(context.Context, *Empty) (*ReleasedTaskLocks, error)
+	DeleteMetric(context.Context, *DeleteMetricRequest) (*Empty, error)
+	ReleaseStaleTaskLocks(context.Context, *Empty) (*ReleasedTaskLocks, error)
 	mustEmbedUnimplementedMonitorServer()
 }
 
@@ -95,6 +251,46 @@ func (UnimplementedMonitorServer) GetConfig(context.Context, *Empty) (*Config, e
 func (UnimplementedMonitorServer) GetTelemetry(*Empty, Monitor_GetTelemetryServer) error {
 	return status.Errorf(codes.Unimplemented, "method GetTelemetry not implemented")
 }
+
+func (UnimplementedMonitorServer) GetRenovateStatus(*Empty, Monitor_GetRenovateStatusServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetRenovateStatus not implemented")
+}
+
+func (UnimplementedMonitorServer) ListMetrics(context.Context, *ListMetricsRequest) (*Metrics, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMetrics not implemented")
+}
+
+func (UnimplementedMonitorServer) GetCluster(*Empty, Monitor_GetClusterServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetCluster not implemented")
+}
+
+func (UnimplementedMonitorServer) ListTasks(context.Context, *Empty) (*Tasks, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTasks not implemented")
+}
+
+func (UnimplementedMonitorServer) TriggerTask(context.Context, *TaskRequest) (*TriggerTaskResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TriggerTask not implemented")
+}
+
+func (UnimplementedMonitorServer) PauseTaskScheduling(context.Context, *TaskRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PauseTaskScheduling not implemented")
+}
+
+func (UnimplementedMonitorServer) ResumeTaskScheduling(context.Context, *TaskRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResumeTaskScheduling not implemented")
+}
+
+func (UnimplementedMonitorServer) PurgeTaskQueue(context.Context, *Empty) (*ReleasedTaskLocks, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurgeTaskQueue not implemented")
+}
+
+func (UnimplementedMonitorServer) DeleteMetric(context.Context, *DeleteMetricRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteMetric not implemented")
+}
+
+func (UnimplementedMonitorServer) ReleaseStaleTaskLocks(context.Context, *Empty) (*ReleasedTaskLocks, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReleaseStaleTaskLocks not implemented")
+}
 func (UnimplementedMonitorServer) mustEmbedUnimplementedMonitorServer() {}
 
 // UnsafeMonitorServer may be embedded to opt out of forward compatibility for this service.
@@ -147,6 +343,192 @@ func (x *monitorGetTelemetryServer) Send(m *Telemetry) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _Monitor_GetRenovateStatus_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MonitorServer).GetRenovateStatus(m, &monitorGetRenovateStatusServer{stream})
+}
+
+type Monitor_GetRenovateStatusServer interface {
+	Send(*RenovateStatus) error
+	grpc.ServerStream
+}
+
+type monitorGetRenovateStatusServer struct {
+	grpc.ServerStream
+}
+
+func (x *monitorGetRenovateStatusServer) Send(m *RenovateStatus) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Monitor_ListMetrics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMetricsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).ListMetrics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/monitor.Monitor/ListMetrics",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).ListMetrics(ctx, req.(*ListMetricsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_GetCluster_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MonitorServer).GetCluster(m, &monitorGetClusterServer{stream})
+}
+
+type Monitor_GetClusterServer interface {
+	Send(*Cluster) error
+	grpc.ServerStream
+}
+
+type monitorGetClusterServer struct {
+	grpc.ServerStream
+}
+
+func (x *monitorGetClusterServer) Send(m *Cluster) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Monitor_ListTasks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).ListTasks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/monitor.Monitor/ListTasks",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).ListTasks(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_TriggerTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).TriggerTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/monitor.Monitor/TriggerTask",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).TriggerTask(ctx, req.(*TaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_PauseTaskScheduling_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).PauseTaskScheduling(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/monitor.Monitor/PauseTaskScheduling",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).PauseTaskScheduling(ctx, req.(*TaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_ResumeTaskScheduling_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).ResumeTaskScheduling(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/monitor.Monitor/ResumeTaskScheduling",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).ResumeTaskScheduling(ctx, req.(*TaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_PurgeTaskQueue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).PurgeTaskQueue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/monitor.Monitor/PurgeTaskQueue",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).PurgeTaskQueue(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_DeleteMetric_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteMetricRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).DeleteMetric(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/monitor.Monitor/DeleteMetric",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).DeleteMetric(ctx, req.(*DeleteMetricRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_ReleaseStaleTaskLocks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).ReleaseStaleTaskLocks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/monitor.Monitor/ReleaseStaleTaskLocks",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).ReleaseStaleTaskLocks(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Monitor_ServiceDesc is the grpc.ServiceDesc for Monitor service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -158,6 +540,38 @@ var Monitor_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetConfig",
 			Handler:    _Monitor_GetConfig_Handler,
 		},
+		{
+			MethodName: "ListMetrics",
+			Handler:    _Monitor_ListMetrics_Handler,
+		},
+		{
+			MethodName: "ListTasks",
+			Handler:    _Monitor_ListTasks_Handler,
+		},
+		{
+			MethodName: "TriggerTask",
+			Handler:    _Monitor_TriggerTask_Handler,
+		},
+		{
+			MethodName: "PauseTaskScheduling",
+			Handler:    _Monitor_PauseTaskScheduling_Handler,
+		},
+		{
+			MethodName: "ResumeTaskScheduling",
+			Handler:    _Monitor_ResumeTaskScheduling_Handler,
+		},
+		{
+			MethodName: "PurgeTaskQueue",
+			Handler:    _Monitor_PurgeTaskQueue_Handler,
+		},
+		{
+			MethodName: "DeleteMetric",
+			Handler:    _Monitor_DeleteMetric_Handler,
+		},
+		{
+			MethodName: "ReleaseStaleTaskLocks",
+			Handler:    _Monitor_ReleaseStaleTaskLocks_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -165,6 +579,16 @@ var Monitor_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _Monitor_GetTelemetry_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "GetRenovateStatus",
+			Handler:       _Monitor_GetRenovateStatus_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GetCluster",
+			Handler:       _Monitor_GetCluster_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "pkg/monitor/protobuf/monitor.proto",
 }
@@ -0,0 +1,268 @@
+package monitor
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Options secures the internal monitoring listener, they are shared by the server and the client.
+type Options struct {
+	// TLSCertFile and TLSKeyFile hold the certificate presented by the server,
+	// or by the client when the server requires one
+	TLSCertFile string
+	TLSKeyFile  string
+
+	// TLSCAFile holds the CA used by the client to verify the server certificate,
+	// setting it on the server makes it require client certificates signed by it
+	TLSCAFile string
+
+	// Token is a bearer token required by the server and sent by the client
+	Token string
+
+	// AdminToken is a bearer token granting the admin permission on top of the read one, it is only
+	// used by the server. The control RPCs require it as soon as authentication is enabled
+	AdminToken string
+
+	// Reflection exposes the gRPC server reflection service, for tools such as grpcurl
+	Reflection bool
+}
+
+// permission is the level of access granted to an authenticated request.
+type permission int
+
+const (
+	permissionRead permission = iota
+	permissionAdmin
+)
+
+// adminMethods lists the RPCs which alter the state of the exporter.
+var adminMethods = map[string]bool{
+	"/monitor.Monitor/TriggerTask":           true,
+	"/monitor.Monitor/PauseTaskScheduling":   true,
+	"/monitor.Monitor/ResumeTaskScheduling":  true,
+	"/monitor.Monitor/PurgeTaskQueue":        true,
+	"/monitor.Monitor/DeleteMetric":          true,
+	"/monitor.Monitor/ReleaseStaleTaskLocks": true,
+}
+
+// authEnabled returns whether the requests must carry a bearer token.
+func (o Options) authEnabled() bool {
+	return o.Token != "" || o.AdminToken != ""
+}
+
+// errTokenRequiresTLS is returned when a bearer token would be sent in plaintext over the network.
+var errTokenRequiresTLS = errors.New("the bearer tokens require TLS, unless the listener is a unix socket")
+
+// checkServerTransport refuses to accept the bearer tokens in plaintext on endpoints other than unix sockets.
+func (o Options) checkServerTransport(scheme string) error {
+	if o.authEnabled() && o.TLSCertFile == "" && scheme != "unix" {
+		return errTokenRequiresTLS
+	}
+
+	return nil
+}
+
+// loadCertPool returns a pool holding the CA certificates of the file.
+func loadCertPool(path string) (*x509.CertPool, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading CA file: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(b) {
+		return nil, fmt.Errorf("no certificate found in CA file '%s'", path)
+	}
+
+	return pool, nil
+}
+
+// serverCredentials returns the transport credentials of the server, plaintext is used unless a certificate is set.
+func (o Options) serverCredentials() (credentials.TransportCredentials, error) {
+	if o.TLSCertFile == "" {
+		return insecure.NewCredentials(), nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(o.TLSCertFile, o.TLSKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("loading server certificate: %w", err)
+	}
+
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if o.TLSCAFile != "" {
+		if cfg.ClientCAs, err = loadCertPool(o.TLSCAFile); err != nil {
+			return nil, err
+		}
+
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return credentials.NewTLS(cfg), nil
+}
+
+// clientCredentials returns the transport credentials of the client, TLS is used as soon as a CA or a certificate is set.
+func (o Options) clientCredentials() (credentials.TransportCredentials, error) {
+	if o.TLSCAFile == "" && o.TLSCertFile == "" {
+		return insecure.NewCredentials(), nil
+	}
+
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if o.TLSCAFile != "" {
+		var err error
+
+		if cfg.RootCAs, err = loadCertPool(o.TLSCAFile); err != nil {
+			return nil, err
+		}
+	}
+
+	if o.TLSCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.TLSCertFile, o.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return credentials.NewTLS(cfg), nil
+}
+
+// serverOptions returns the options enforcing the transport security and the authentication of the requests.
+func (o Options) serverOptions() ([]grpc.ServerOption, error) {
+	creds, err := o.serverCredentials()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []grpc.ServerOption{grpc.Creds(creds)}
+
+	if o.authEnabled() {
+		opts = append(
+			opts,
+			grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+				if err := o.authorize(ctx, info.FullMethod); err != nil {
+					return nil, err
+				}
+
+				return handler(ctx, req)
+			}),
+			grpc.StreamInterceptor(func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+				if err := o.authorize(ss.Context(), info.FullMethod); err != nil {
+					return err
+				}
+
+				return handler(srv, ss)
+			}),
+		)
+	}
+
+	return opts, nil
+}
+
+// dialOptions returns the options matching the ones of the server listening on an endpoint of the given scheme.
+func (o Options) dialOptions(scheme string) ([]grpc.DialOption, error) {
+	creds, err := o.clientCredentials()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+
+	if o.Token != "" {
+		if o.TLSCAFile == "" && o.TLSCertFile == "" && scheme != "unix" {
+			return nil, errTokenRequiresTLS
+		}
+
+		opts = append(opts, grpc.WithPerRPCCredentials(bearerToken{
+			token:                    o.Token,
+			requireTransportSecurity: scheme != "unix",
+		}))
+	}
+
+	return opts, nil
+}
+
+// authorize checks that the request is allowed to call the method. The health service is left
+// open, as the probes of the orchestrators cannot send a bearer token.
+func (o Options) authorize(ctx context.Context, method string) error {
+	if strings.HasPrefix(method, "/"+healthpb.Health_ServiceDesc.ServiceName+"/") {
+		return nil
+	}
+
+	p, err := o.authenticate(ctx)
+	if err != nil {
+		return err
+	}
+
+	if adminMethods[method] && p < permissionAdmin {
+		return status.Error(codes.PermissionDenied, "the admin permission is required")
+	}
+
+	return nil
+}
+
+// authenticate checks the bearer token found in the metadata of the request and returns the permission it grants.
+func (o Options) authenticate(ctx context.Context) (permission, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	for _, v := range md.Get("authorization") {
+		t, ok := strings.CutPrefix(v, "Bearer ")
+		if !ok {
+			continue
+		}
+
+		if tokenMatches(t, o.AdminToken) {
+			return permissionAdmin, nil
+		}
+
+		if tokenMatches(t, o.Token) {
+			return permissionRead, nil
+		}
+	}
+
+	return permissionRead, status.Error(codes.Unauthenticated, "invalid or missing bearer token")
+}
+
+// tokenMatches compares the tokens in constant time, an unset token never matches.
+func tokenMatches(t, token string) bool {
+	return token != "" && subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1
+}
+
+// bearerToken implements credentials.PerRPCCredentials.
+type bearerToken struct {
+	token string
+
+	// requireTransportSecurity is only unset for unix sockets, over which the token may be sent in plaintext
+	requireTransportSecurity bool
+}
+
+// GetRequestMetadata implements credentials.PerRPCCredentials.
+func (t bearerToken) GetRequestMetadata(_ context.Context, _ ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + t.token}, nil
+}
+
+// RequireTransportSecurity implements credentials.PerRPCCredentials.
+func (t bearerToken) RequireTransportSecurity() bool {
+	return t.requireTransportSecurity
+}
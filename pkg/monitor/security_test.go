@@ -0,0 +1,219 @@
+package monitor
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/config"
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/controller"
+	pb "github.com/xnok/mend-renovate-ce-ee-exporter/pkg/monitor/protobuf"
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/schemas"
+)
+
+// testPKI holds the paths of a CA and of the certificates it signed for the server and the client.
+type testPKI struct {
+	ca, serverCert, serverKey, clientCert, clientKey string
+}
+
+func newTestPKI(t *testing.T) (pki testPKI) {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	writePEM := func(name, blockType string, b []byte) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: b}), 0o600))
+
+		return path
+	}
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+
+	pki.ca = writePEM("ca.pem", "CERTIFICATE", caDER)
+
+	issue := func(name string, serial int64, usage x509.ExtKeyUsage) (string, string) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: name},
+			DNSNames:     []string{"bufnet"},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		}, caTemplate, &key.PublicKey, caKey)
+		require.NoError(t, err)
+
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		require.NoError(t, err)
+
+		return writePEM(name+".pem", "CERTIFICATE", der), writePEM(name+"-key.pem", "EC PRIVATE KEY", keyDER)
+	}
+
+	pki.serverCert, pki.serverKey = issue("server", 2, x509.ExtKeyUsageServerAuth)
+	pki.clientCert, pki.clientKey = issue("client", 3, x509.ExtKeyUsageClientAuth)
+
+	return
+}
+
+func TestServerToken(t *testing.T) {
+	c, err := controller.New(context.Background(), config.New(), "test")
+	require.NoError(t, err)
+
+	// Unary calls
+	_, err = newSecuredTestClient(t, &c, Options{Token: "s3cr3t"}, Options{}).GetConfig(context.Background(), &pb.Empty{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = newSecuredTestClient(t, &c, Options{Token: "s3cr3t"}, Options{Token: "wrong"}).GetConfig(context.Background(), &pb.Empty{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = newSecuredTestClient(t, &c, Options{Token: "s3cr3t"}, Options{Token: "s3cr3t"}).GetConfig(context.Background(), &pb.Empty{})
+	assert.NoError(t, err)
+
+	// Streams
+	stream, err := newSecuredTestClient(t, &c, Options{Token: "s3cr3t"}, Options{}).GetTelemetry(context.Background(), &pb.Empty{})
+	require.NoError(t, err)
+
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	stream, err = newSecuredTestClient(t, &c, Options{Token: "s3cr3t"}, Options{Token: "s3cr3t"}).GetTelemetry(context.Background(), &pb.Empty{})
+	require.NoError(t, err)
+
+	_, err = stream.Recv()
+	assert.NoError(t, err)
+}
+
+func TestServerAdminToken(t *testing.T) {
+	ctx := context.Background()
+
+	c, err := controller.New(ctx, config.New(), "test")
+	require.NoError(t, err)
+
+	c.RegisterTasks(schemas.TaskTypeGarbageCollectMetrics, func() error { return nil })
+
+	serverOpts := Options{Token: "r34d", AdminToken: "s3cr3t"}
+	req := &pb.TaskRequest{TaskType: string(schemas.TaskTypeGarbageCollectMetrics)}
+
+	// The read only token cannot use the control RPCs
+	client := newSecuredTestClient(t, &c, serverOpts, Options{Token: "r34d"})
+
+	_, err = client.GetConfig(ctx, &pb.Empty{})
+	assert.NoError(t, err)
+
+	_, err = client.PauseTaskScheduling(ctx, req)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// The admin token can use all of them
+	client = newSecuredTestClient(t, &c, serverOpts, Options{Token: "s3cr3t"})
+
+	_, err = client.GetConfig(ctx, &pb.Empty{})
+	assert.NoError(t, err)
+
+	_, err = client.PauseTaskScheduling(ctx, req)
+	assert.NoError(t, err)
+
+	// Without an admin token, the control RPCs are denied to everyone once authentication is enabled
+	_, err = newSecuredTestClient(t, &c, Options{Token: "r34d"}, Options{Token: "r34d"}).ResumeTaskScheduling(ctx, req)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// They remain available when authentication is disabled
+	_, err = newTestClient(t, &c).ResumeTaskScheduling(ctx, req)
+	assert.NoError(t, err)
+}
+
+func TestServerTLS(t *testing.T) {
+	pki := newTestPKI(t)
+
+	c, err := controller.New(context.Background(), config.New(), "test")
+	require.NoError(t, err)
+
+	serverTLS := Options{TLSCertFile: pki.serverCert, TLSKeyFile: pki.serverKey}
+	serverMTLS := Options{TLSCertFile: pki.serverCert, TLSKeyFile: pki.serverKey, TLSCAFile: pki.ca}
+	clientTLS := Options{TLSCAFile: pki.ca}
+	clientMTLS := Options{TLSCAFile: pki.ca, TLSCertFile: pki.clientCert, TLSKeyFile: pki.clientKey}
+
+	_, err = newSecuredTestClient(t, &c, serverTLS, clientTLS).GetConfig(context.Background(), &pb.Empty{})
+	assert.NoError(t, err)
+
+	// Plaintext clients are rejected
+	_, err = newSecuredTestClient(t, &c, serverTLS, Options{}).GetConfig(context.Background(), &pb.Empty{})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	// Client certificates are required for mTLS
+	_, err = newSecuredTestClient(t, &c, serverMTLS, clientTLS).GetConfig(context.Background(), &pb.Empty{})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	_, err = newSecuredTestClient(t, &c, serverMTLS, clientMTLS).GetConfig(context.Background(), &pb.Empty{})
+	assert.NoError(t, err)
+}
+
+func TestTokenRequiresTLS(t *testing.T) {
+	pki := newTestPKI(t)
+
+	// The tokens are only sent and accepted in plaintext over unix sockets
+	_, err := Options{Token: "s3cr3t"}.dialOptions("tcp")
+	assert.ErrorIs(t, err, errTokenRequiresTLS)
+
+	_, err = Options{Token: "s3cr3t"}.dialOptions("unix")
+	assert.NoError(t, err)
+
+	_, err = Options{Token: "s3cr3t", TLSCAFile: pki.ca}.dialOptions("tcp")
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, Options{AdminToken: "s3cr3t"}.checkServerTransport("tcp"), errTokenRequiresTLS)
+	assert.NoError(t, Options{AdminToken: "s3cr3t"}.checkServerTransport("unix"))
+	assert.NoError(t, Options{AdminToken: "s3cr3t", TLSCertFile: pki.serverCert}.checkServerTransport("tcp"))
+	assert.NoError(t, Options{}.checkServerTransport("tcp"))
+
+	assert.True(t, bearerToken{token: "s3cr3t", requireTransportSecurity: true}.RequireTransportSecurity())
+}
+
+func TestServerTokenOverTLS(t *testing.T) {
+	pki := newTestPKI(t)
+
+	c, err := controller.New(context.Background(), config.New(), "test")
+	require.NoError(t, err)
+
+	// The token is sent over a network endpoint once TLS is set up
+	conn := newTestConnWithScheme(
+		t, &c,
+		Options{TLSCertFile: pki.serverCert, TLSKeyFile: pki.serverKey, Token: "s3cr3t"},
+		Options{TLSCAFile: pki.ca, Token: "s3cr3t"},
+		"tcp",
+	)
+
+	_, err = pb.NewMonitorClient(conn).GetConfig(context.Background(), &pb.Empty{})
+	assert.NoError(t, err)
+}
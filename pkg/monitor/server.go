@@ -1,43 +1,73 @@
 package monitor
 
 import (
+	"context"
+	"errors"
 	"net"
 	"net/url"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
-	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/config"
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/controller"
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/metrics"
 	pb "github.com/xnok/mend-renovate-ce-ee-exporter/pkg/monitor/protobuf"
 	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/schemas"
-	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/store"
 )
 
+// telemetryInterval is the interval at which the telemetry snapshots are streamed.
+const telemetryInterval = time.Second
+
 // Server ..
 type Server struct {
 	pb.UnimplementedMonitorServer
 
-	cfg                      config.Config
-	store                    store.Store
-	taskSchedulingMonitoring map[schemas.TaskType]*schemas.TaskSchedulingStatus
+	// controller gives access to the live config and store, which can change on reloads
+	controller *controller.Controller
+	opts       Options
 }
 
 // NewServer ..
 func NewServer(
-	c config.Config,
-	st store.Store,
-	tsm map[schemas.TaskType]*schemas.TaskSchedulingStatus,
+	c *controller.Controller,
+	opts Options,
 ) (s *Server) {
 	s = &Server{
-		cfg:                      c,
-		store:                    st,
-		taskSchedulingMonitoring: tsm,
+		controller: c,
+		opts:       opts,
 	}
 
 	return
 }
 
+// newGRPCServer returns a gRPC server exposing the monitor service.
+func (s *Server) newGRPCServer() (*grpc.Server, error) {
+	opts, err := s.opts.serverOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	grpcServer := grpc.NewServer(opts...)
+	pb.RegisterMonitorServer(grpcServer, s)
+	healthpb.RegisterHealthServer(grpcServer, &healthServer{controller: s.controller})
+
+	if s.opts.Reflection {
+		reflection.Register(grpcServer)
+	}
+
+	return grpcServer, nil
+}
+
 // Serve ..
 func (s *Server) Serve(url *url.URL) {
 	if url == nil {
@@ -48,19 +78,26 @@ func (s *Server) Serve(url *url.URL) {
 
 	log.WithFields(
 		log.Fields{
-			"scheme": url.Scheme,
-			"host":   url.Host,
-			"path":   url.Path,
+			"scheme":     url.Scheme,
+			"host":       url.Host,
+			"path":       url.Path,
+			"tls":        s.opts.TLSCertFile != "",
+			"mtls":       s.opts.TLSCertFile != "" && s.opts.TLSCAFile != "",
+			"auth":       s.opts.authEnabled(),
+			"reflection": s.opts.Reflection,
 		},
 	).Info("internal monitoring listener set")
 
-	grpcServer := grpc.NewServer()
-	pb.RegisterMonitorServer(grpcServer, s)
+	if err := s.opts.checkServerTransport(url.Scheme); err != nil {
+		log.WithError(err).Fatal("configuring the internal monitoring listener")
+	}
 
-	var (
-		l   net.Listener
-		err error
-	)
+	grpcServer, err := s.newGRPCServer()
+	if err != nil {
+		log.WithError(err).Fatal("configuring the internal monitoring listener")
+	}
+
+	var l net.Listener
 
 	switch url.Scheme {
 	case "unix":
@@ -99,3 +136,399 @@ func (s *Server) Serve(url *url.URL) {
 		log.WithError(err).Fatal()
 	}
 }
+
+// GetConfig returns the current config of the exporter, with the secrets redacted.
+func (s *Server) GetConfig(_ context.Context, _ *pb.Empty) (*pb.Config, error) {
+	return &pb.Config{
+		Content: s.controller.Config().ToYAML(),
+	}, nil
+}
+
+// GetTelemetry streams snapshots of the exporter telemetry until the client goes away.
+func (s *Server) GetTelemetry(_ *pb.Empty, ts pb.Monitor_GetTelemetryServer) (err error) {
+	ctx := ts.Context()
+
+	ticker := time.NewTicker(telemetryInterval)
+	defer ticker.Stop()
+
+	for {
+		var telemetry *pb.Telemetry
+
+		if telemetry, err = s.telemetry(ctx); err != nil {
+			return
+		}
+
+		if err = ts.Send(telemetry); err != nil {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// telemetry builds a snapshot of the exporter telemetry.
+func (s *Server) telemetry(ctx context.Context) (t *pb.Telemetry, err error) {
+	t = &pb.Telemetry{
+		Metrics: &pb.Entity{},
+	}
+
+	st := s.controller.Store
+
+	var queuedTasksCount uint64
+
+	if queuedTasksCount, err = st.CurrentlyQueuedTasksCount(ctx); err != nil {
+		return
+	}
+
+	if bufferSize := s.controller.TaskController.Queue.Options().BufferSize; bufferSize > 0 {
+		t.TasksBufferUsage = float64(queuedTasksCount) / float64(bufferSize)
+	}
+
+	if t.TasksExecutedCount, err = st.ExecutedTasksCount(ctx); err != nil {
+		return
+	}
+
+	if t.Metrics.Count, err = st.MetricsCount(ctx); err != nil {
+		return
+	}
+
+	var pull, gc schemas.TaskSchedulingStatus
+
+	if pull, err = s.pullSchedulingStatus(ctx); err != nil {
+		return
+	}
+
+	if gc, err = st.TaskSchedulingStatus(ctx, schemas.TaskTypeGarbageCollectMetrics); err != nil {
+		return
+	}
+
+	t.Metrics.LastPull = timestamppb.New(pull.Last)
+	t.Metrics.NextPull = timestamppb.New(pull.Next)
+	t.Metrics.LastGc = timestamppb.New(gc.Last)
+	t.Metrics.NextGc = timestamppb.New(gc.Next)
+
+	return
+}
+
+// pullSchedulingStatus merges the scheduling status of the tasks pulling the metrics of
+// the sources, keeping the most recent execution and the upcoming one.
+func (s *Server) pullSchedulingStatus(ctx context.Context) (status schemas.TaskSchedulingStatus, err error) {
+	for _, src := range s.controller.Sources {
+		for tt := range src.Schedule() {
+			var ts schemas.TaskSchedulingStatus
+
+			if ts, err = s.controller.Store.TaskSchedulingStatus(ctx, tt); err != nil {
+				return
+			}
+
+			if ts.Last.After(status.Last) {
+				status.Last = ts.Last
+			}
+
+			if !ts.Next.IsZero() && (status.Next.IsZero() || ts.Next.Before(status.Next)) {
+				status.Next = ts.Next
+			}
+		}
+	}
+
+	return
+}
+
+// GetRenovateStatus streams the last status pulled from Mend Renovate until the client goes away.
+func (s *Server) GetRenovateStatus(_ *pb.Empty, rs pb.Monitor_GetRenovateStatusServer) (err error) {
+	ctx := rs.Context()
+
+	ticker := time.NewTicker(telemetryInterval)
+	defer ticker.Stop()
+
+	for {
+		var status *pb.RenovateStatus
+
+		if status, err = s.renovateStatus(ctx); err != nil {
+			return
+		}
+
+		if err = rs.Send(status); err != nil {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// renovateStatus converts the last pulled status, it is left empty until
+// the status gets pulled successfully.
+func (s *Server) renovateStatus(ctx context.Context) (*pb.RenovateStatus, error) {
+	ps, _, err := metrics.LoadPulledStatus(ctx, s.controller.Store)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &pb.RenovateStatus{
+		PulledAt:    timestamppb.New(ps.PulledAt),
+		QueueLength: int64(ps.Status.Jobs.QueueLength),
+		CurrentJob: &pb.RenovateJob{
+			Repository: ps.Status.Worker.CurrentJob.Repository,
+			Reason:     ps.Status.Worker.CurrentJob.Reason,
+			Priority:   int64(ps.Status.Worker.CurrentJob.Priority),
+		},
+		CurrentJobStartedAt:   timestamppb.New(ps.Status.Worker.CurrentJobStart),
+		SchedulerCron:         ps.Status.Scheduler.Cron,
+		SchedulerPlatform:     ps.Status.Scheduler.Platform,
+		LastWebhookReceivedAt: timestamppb.New(ps.Status.Webhooks.LastWebhookReceived),
+		ScrapeLatency:         durationpb.New(ps.Latency),
+	}
+
+	for _, j := range ps.Status.JobsInProgress {
+		status.JobsInProgress = append(status.JobsInProgress, &pb.RenovateJobInProgress{
+			Repository: j.Repository,
+			StartedAt:  timestamppb.New(j.Started),
+		})
+	}
+
+	return status, nil
+}
+
+// ListMetrics returns the metrics currently held in the store, sorted by name. The metrics
+// can be filtered using space separated terms, which must all be found within either the name,
+// the key or one of the name=value label pairs of a metric, regardless of the case.
+func (s *Server) ListMetrics(ctx context.Context, req *pb.ListMetricsRequest) (*pb.Metrics, error) {
+	metrics, err := s.controller.Store.Metrics(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := strings.Fields(strings.ToLower(req.GetFilter()))
+	res := &pb.Metrics{}
+
+	for k, m := range metrics {
+		metric := &pb.Metric{
+			Key:       string(k),
+			Name:      s.controller.MetricName(m.Kind),
+			Labels:    m.Labels,
+			Value:     m.Value,
+			UpdatedAt: timestamppb.New(m.UpdatedAt),
+		}
+
+		if metricMatches(metric, terms) {
+			res.Metrics = append(res.Metrics, metric)
+		}
+	}
+
+	sort.Slice(res.Metrics, func(i, j int) bool {
+		if res.Metrics[i].Name != res.Metrics[j].Name {
+			return res.Metrics[i].Name < res.Metrics[j].Name
+		}
+
+		return res.Metrics[i].Key < res.Metrics[j].Key
+	})
+
+	return res, nil
+}
+
+func metricMatches(m *pb.Metric, terms []string) bool {
+	fields := []string{strings.ToLower(m.Name), strings.ToLower(m.Key)}
+	for name, value := range m.Labels {
+		fields = append(fields, strings.ToLower(name+"="+value))
+	}
+
+terms:
+	for _, term := range terms {
+		for _, f := range fields {
+			if strings.Contains(f, term) {
+				continue terms
+			}
+		}
+
+		return false
+	}
+
+	return true
+}
+
+// GetCluster streams the replicas currently running and the task locks they hold until the client goes away.
+func (s *Server) GetCluster(_ *pb.Empty, cs pb.Monitor_GetClusterServer) (err error) {
+	ctx := cs.Context()
+
+	ticker := time.NewTicker(telemetryInterval)
+	defer ticker.Stop()
+
+	for {
+		var cluster *pb.Cluster
+
+		if cluster, err = s.cluster(ctx); err != nil {
+			return
+		}
+
+		if err = cs.Send(cluster); err != nil {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// cluster builds a snapshot of the replicas, the locks held by processes which are no longer running are flagged as stale.
+func (s *Server) cluster(ctx context.Context) (*pb.Cluster, error) {
+	replicas, err := s.controller.Replicas(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	locks, err := s.controller.Store.TaskLocks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cluster := &pb.Cluster{}
+
+	for _, r := range replicas {
+		cluster.Replicas = append(cluster.Replicas, &pb.Replica{
+			Uuid:         r.UUID,
+			Version:      r.Version,
+			Hostname:     r.Hostname,
+			StartedAt:    timestamppb.New(r.StartedAt),
+			Leader:       r.Leader,
+			LastTaskType: string(r.LastTaskType),
+			LastTaskAt:   timestamppb.New(r.LastTaskAt),
+			Current:      r.UUID == s.controller.UUID.String(),
+		})
+	}
+
+	liveness := s.controller.Liveness(replicas)
+
+	for _, l := range locks {
+		alive, err := liveness.Alive(ctx, l.Owner)
+		if err != nil {
+			return nil, err
+		}
+
+		cluster.TaskLocks = append(cluster.TaskLocks, &pb.TaskLock{
+			TaskType: string(l.TaskType),
+			UniqueId: l.UniqueID,
+			Owner:    l.Owner,
+			Stale:    !alive,
+		})
+	}
+
+	sort.Slice(cluster.Replicas, func(i, j int) bool {
+		return cluster.Replicas[i].StartedAt.AsTime().Before(cluster.Replicas[j].StartedAt.AsTime())
+	})
+
+	sort.Slice(cluster.TaskLocks, func(i, j int) bool {
+		if cluster.TaskLocks[i].TaskType != cluster.TaskLocks[j].TaskType {
+			return cluster.TaskLocks[i].TaskType < cluster.TaskLocks[j].TaskType
+		}
+
+		return cluster.TaskLocks[i].UniqueId < cluster.TaskLocks[j].UniqueId
+	})
+
+	return cluster, nil
+}
+
+// ListTasks returns the registered task types along with their scheduling status.
+func (s *Server) ListTasks(ctx context.Context, _ *pb.Empty) (*pb.Tasks, error) {
+	res := &pb.Tasks{}
+
+	for _, tt := range s.controller.TaskTypes() {
+		status, err := s.controller.Store.TaskSchedulingStatus(ctx, tt)
+		if err != nil {
+			return nil, err
+		}
+
+		res.Tasks = append(res.Tasks, &pb.Task{
+			TaskType:      string(tt),
+			Paused:        status.Paused,
+			LastScheduled: timestamppb.New(status.Last),
+			NextScheduled: timestamppb.New(status.Next),
+		})
+	}
+
+	return res, nil
+}
+
+// TriggerTask enqueues the task right away.
+func (s *Server) TriggerTask(ctx context.Context, req *pb.TaskRequest) (*pb.TriggerTaskResponse, error) {
+	skipReason, err := s.controller.TriggerTask(ctx, schemas.TaskType(req.GetTaskType()))
+	if err != nil {
+		return nil, controlError(err)
+	}
+
+	res := &pb.TriggerTaskResponse{Status: controller.AdminTaskStatusScheduled}
+	if skipReason != "" {
+		res.Status = skipReason
+	}
+
+	return res, nil
+}
+
+// PauseTaskScheduling suspends the periodic scheduling of the task.
+func (s *Server) PauseTaskScheduling(ctx context.Context, req *pb.TaskRequest) (*pb.Empty, error) {
+	return &pb.Empty{}, controlError(s.controller.SetTaskSchedulingPaused(ctx, schemas.TaskType(req.GetTaskType()), true))
+}
+
+// ResumeTaskScheduling resumes the periodic scheduling of the task.
+func (s *Server) ResumeTaskScheduling(ctx context.Context, req *pb.TaskRequest) (*pb.Empty, error) {
+	return &pb.Empty{}, controlError(s.controller.SetTaskSchedulingPaused(ctx, schemas.TaskType(req.GetTaskType()), false))
+}
+
+// PurgeTaskQueue drops the tasks waiting in the queue and releases their locks.
+func (s *Server) PurgeTaskQueue(ctx context.Context, _ *pb.Empty) (*pb.ReleasedTaskLocks, error) {
+	released, err := s.controller.PurgeTaskQueue(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ReleasedTaskLocks{Count: int64(released)}, nil
+}
+
+// DeleteMetric removes the metric from the store, until it gets pulled again.
+func (s *Server) DeleteMetric(ctx context.Context, req *pb.DeleteMetricRequest) (*pb.Empty, error) {
+	k := schemas.MetricKey(req.GetKey())
+
+	exists, err := s.controller.Store.MetricExists(ctx, k)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "metric '%s' not found", k)
+	}
+
+	log.WithContext(ctx).
+		WithField("metric_key", k).
+		Info("metric deleted")
+
+	return &pb.Empty{}, s.controller.Store.DelMetric(ctx, k)
+}
+
+// ReleaseStaleTaskLocks removes the task locks held by processes which are no longer running.
+func (s *Server) ReleaseStaleTaskLocks(ctx context.Context, _ *pb.Empty) (*pb.ReleasedTaskLocks, error) {
+	released, err := s.controller.ReleaseStaleTaskLocks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ReleasedTaskLocks{Count: int64(released)}, nil
+}
+
+// controlError maps the errors of the controller onto gRPC codes.
+func controlError(err error) error {
+	if errors.Is(err, controller.ErrUnknownTaskType) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+
+	return err
+}
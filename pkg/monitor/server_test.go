@@ -0,0 +1,393 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/config"
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/controller"
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/metrics"
+	pb "github.com/xnok/mend-renovate-ce-ee-exporter/pkg/monitor/protobuf"
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/schemas"
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/store"
+)
+
+// newTestClient serves the monitor service of the controller in-process and returns a client connected to it.
+func newTestClient(t *testing.T, c *controller.Controller) pb.MonitorClient {
+	t.Helper()
+
+	return newSecuredTestClient(t, c, Options{}, Options{})
+}
+
+// newSecuredTestClient does the same as newTestClient, using the provided options on each side.
+func newSecuredTestClient(t *testing.T, c *controller.Controller, serverOpts, clientOpts Options) pb.MonitorClient {
+	t.Helper()
+
+	return pb.NewMonitorClient(newTestConn(t, c, serverOpts, clientOpts))
+}
+
+// newTestConn serves all the services of the listener in-process and returns a connection to them.
+func newTestConn(t *testing.T, c *controller.Controller, serverOpts, clientOpts Options) *grpc.ClientConn {
+	t.Helper()
+
+	// The in-process connection does not go over the network, like unix sockets
+	return newTestConnWithScheme(t, c, serverOpts, clientOpts, "unix")
+}
+
+// newTestConnWithScheme does the same as newTestConn, the client being configured for an endpoint of the given scheme.
+func newTestConnWithScheme(t *testing.T, c *controller.Controller, serverOpts, clientOpts Options, scheme string) *grpc.ClientConn {
+	t.Helper()
+
+	l := bufconn.Listen(1024 * 1024)
+	grpcServer, err := NewServer(c, serverOpts).newGRPCServer()
+	require.NoError(t, err)
+
+	go func() {
+		_ = grpcServer.Serve(l)
+	}()
+
+	t.Cleanup(grpcServer.Stop)
+
+	dialOpts, err := clientOpts.dialOptions(scheme)
+	require.NoError(t, err)
+
+	conn, err := grpc.DialContext(
+		context.Background(),
+		"bufnet",
+		append(
+			dialOpts,
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return l.DialContext(ctx)
+			}),
+		)...,
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
+
+func TestServerGetConfig(t *testing.T) {
+	cfg := config.New()
+	cfg.Server.Webhook.Enabled = true
+	cfg.Server.Webhook.SecretToken = "supersecret"
+	cfg.Clients.MendRenovate.Token = "mendsecret"
+
+	c, err := controller.New(context.Background(), cfg, "test")
+	require.NoError(t, err)
+
+	res, err := newTestClient(t, &c).GetConfig(context.Background(), &pb.Empty{})
+	require.NoError(t, err)
+	assert.Contains(t, res.GetContent(), "listen_address: :8080")
+	assert.NotContains(t, res.GetContent(), "supersecret")
+	assert.NotContains(t, res.GetContent(), "mendsecret")
+}
+
+func TestServerGetTelemetry(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c, err := controller.New(ctx, config.New(), "test")
+	require.NoError(t, err)
+
+	require.NoError(t, c.Store.SetMetric(ctx, schemas.Metric{Kind: 1, Value: 1}))
+	require.NoError(t, c.Store.SetMetric(ctx, schemas.Metric{Kind: 2, Value: 1}))
+
+	next := time.Now().Add(time.Minute).Truncate(time.Second)
+	require.NoError(t, c.Store.SetTaskSchedulingNext(ctx, schemas.TaskTypeGarbageCollectMetrics, next))
+
+	// Tasks queued by any of the replicas are accounted in the buffer usage
+	_, err = c.Store.QueueTask(ctx, "foo", "_", "other")
+	require.NoError(t, err)
+
+	stream, err := newTestClient(t, &c).GetTelemetry(ctx, &pb.Empty{})
+	require.NoError(t, err)
+
+	telemetry, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), telemetry.GetMetrics().GetCount())
+	assert.Equal(t, uint64(0), telemetry.GetTasksExecutedCount())
+	assert.Equal(t, 1/float64(c.TaskController.Queue.Options().BufferSize), telemetry.GetTasksBufferUsage())
+	assert.True(t, next.Equal(telemetry.GetMetrics().GetNextGc().AsTime()))
+
+	// Snapshots keep on being streamed
+	require.NoError(t, c.Store.SetMetric(ctx, schemas.Metric{Kind: 3, Value: 1}))
+
+	telemetry, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), telemetry.GetMetrics().GetCount())
+}
+
+func TestServerGetRenovateStatus(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c, err := controller.New(ctx, config.New(), "test")
+	require.NoError(t, err)
+
+	stream, err := newTestClient(t, &c).GetRenovateStatus(ctx, &pb.Empty{})
+	require.NoError(t, err)
+
+	// Nothing has been pulled yet
+	status, err := stream.Recv()
+	require.NoError(t, err)
+	assert.True(t, status.GetPulledAt().AsTime().IsZero())
+	assert.Empty(t, status.GetJobsInProgress())
+
+	var ps metrics.PulledStatus
+	ps.PulledAt = time.Now().Truncate(time.Second)
+	ps.Latency = 150 * time.Millisecond
+	ps.Status.Jobs.QueueLength = 4
+	ps.Status.Worker.CurrentJob.Repository = "foo/bar"
+	ps.Status.Worker.CurrentJob.Reason = "webhook"
+	ps.Status.Worker.CurrentJob.Priority = 2
+	ps.Status.Scheduler.Cron = "0 * * * *"
+	ps.Status.Scheduler.Platform = "github"
+	ps.Status.JobsInProgress = append(ps.Status.JobsInProgress, struct {
+		Repository string    `json:"repository"`
+		Started    time.Time `json:"started"`
+	}{Repository: "foo/bar", Started: ps.PulledAt})
+
+	b, err := json.Marshal(ps)
+	require.NoError(t, err)
+	require.NoError(t, c.Store.SetSourceStatus(ctx, "mend_renovate", b))
+
+	status, err = stream.Recv()
+	require.NoError(t, err)
+	assert.True(t, ps.PulledAt.Equal(status.GetPulledAt().AsTime()))
+	assert.Equal(t, int64(4), status.GetQueueLength())
+	assert.Equal(t, 150*time.Millisecond, status.GetScrapeLatency().AsDuration())
+	assert.Equal(t, "foo/bar", status.GetCurrentJob().GetRepository())
+	assert.Equal(t, "webhook", status.GetCurrentJob().GetReason())
+	assert.Equal(t, int64(2), status.GetCurrentJob().GetPriority())
+	assert.Equal(t, "0 * * * *", status.GetSchedulerCron())
+	assert.Equal(t, "github", status.GetSchedulerPlatform())
+	require.Len(t, status.GetJobsInProgress(), 1)
+	assert.Equal(t, "foo/bar", status.GetJobsInProgress()[0].GetRepository())
+}
+
+func TestServerListMetrics(t *testing.T) {
+	ctx := context.Background()
+
+	c, err := controller.New(ctx, config.New(), "test")
+	require.NoError(t, err)
+
+	c.RegisterCollector(ctx, func() controller.RegistryCollectors {
+		return controller.RegistryCollectors{
+			100: controller.NewGaugeVecCollector(prometheus.GaugeOpts{Name: "foo_total"}, []string{"repository"}),
+			101: controller.NewGaugeVecCollector(prometheus.GaugeOpts{Name: "bar_total"}, []string{"repository"}),
+		}
+	})
+
+	foo := schemas.Metric{Kind: 100, Labels: prometheus.Labels{"repository": "foo/foo"}, Value: 1}
+	require.NoError(t, c.Store.SetMetric(ctx, foo))
+	require.NoError(t, c.Store.SetMetric(ctx, schemas.Metric{Kind: 100, Labels: prometheus.Labels{"repository": "foo/bar"}, Value: 2}))
+	require.NoError(t, c.Store.SetMetric(ctx, schemas.Metric{Kind: 101, Labels: prometheus.Labels{"repository": "foo/foo"}, Value: 3}))
+
+	client := newTestClient(t, &c)
+
+	res, err := client.ListMetrics(ctx, &pb.ListMetricsRequest{})
+	require.NoError(t, err)
+	require.Len(t, res.GetMetrics(), 3)
+	assert.Equal(t, "bar_total", res.GetMetrics()[0].GetName())
+	assert.Equal(t, "foo_total", res.GetMetrics()[1].GetName())
+
+	res, err = client.ListMetrics(ctx, &pb.ListMetricsRequest{Filter: "FOO_ repository=foo/foo"})
+	require.NoError(t, err)
+	require.Len(t, res.GetMetrics(), 1)
+	assert.Equal(t, string(foo.Key()), res.GetMetrics()[0].GetKey())
+	assert.Equal(t, map[string]string{"repository": "foo/foo"}, res.GetMetrics()[0].GetLabels())
+	assert.Equal(t, float64(1), res.GetMetrics()[0].GetValue())
+	assert.False(t, res.GetMetrics()[0].GetUpdatedAt().AsTime().IsZero())
+
+	res, err = client.ListMetrics(ctx, &pb.ListMetricsRequest{Filter: "baz"})
+	require.NoError(t, err)
+	assert.Empty(t, res.GetMetrics())
+}
+
+func TestServerGetCluster(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c, err := controller.New(ctx, config.New(), "v1.2.3")
+	require.NoError(t, err)
+
+	_, err = c.Store.QueueTask(ctx, "foo", "_", c.UUID.String())
+	require.NoError(t, err)
+	_, err = c.Store.QueueTask(ctx, "bar", "_", "dead")
+	require.NoError(t, err)
+
+	stream, err := newTestClient(t, &c).GetCluster(ctx, &pb.Empty{})
+	require.NoError(t, err)
+
+	cluster, err := stream.Recv()
+	require.NoError(t, err)
+
+	require.Len(t, cluster.GetReplicas(), 1)
+	assert.Equal(t, c.UUID.String(), cluster.GetReplicas()[0].GetUuid())
+	assert.Equal(t, "v1.2.3", cluster.GetReplicas()[0].GetVersion())
+	assert.True(t, cluster.GetReplicas()[0].GetLeader())
+	assert.True(t, cluster.GetReplicas()[0].GetCurrent())
+
+	require.Len(t, cluster.GetTaskLocks(), 2)
+	assert.Equal(t, "bar", cluster.GetTaskLocks()[0].GetTaskType())
+	assert.True(t, cluster.GetTaskLocks()[0].GetStale())
+	assert.Equal(t, "foo", cluster.GetTaskLocks()[1].GetTaskType())
+	assert.False(t, cluster.GetTaskLocks()[1].GetStale())
+}
+
+func TestServerGetClusterKeepalive(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mr := miniredis.RunT(t)
+
+	cfg := config.New()
+	cfg.Redis.URL = "redis://" + mr.Addr()
+
+	c, err := controller.New(ctx, cfg, "v1.2.3")
+	require.NoError(t, err)
+
+	s := c.Store.(*store.Redis)
+
+	// Processes running older versions only set their keepalive, their locks are not stale
+	_, err = s.SetKeepalive(ctx, "legacy", time.Minute)
+	require.NoError(t, err)
+
+	for _, owner := range []string{"legacy", "dead"} {
+		_, err = s.QueueTask(ctx, schemas.TaskType(owner), "_", owner)
+		require.NoError(t, err)
+	}
+
+	stream, err := newTestClient(t, &c).GetCluster(ctx, &pb.Empty{})
+	require.NoError(t, err)
+
+	cluster, err := stream.Recv()
+	require.NoError(t, err)
+
+	require.Len(t, cluster.GetTaskLocks(), 2)
+	assert.Equal(t, "dead", cluster.GetTaskLocks()[0].GetOwner())
+	assert.True(t, cluster.GetTaskLocks()[0].GetStale())
+	assert.Equal(t, "legacy", cluster.GetTaskLocks()[1].GetOwner())
+	assert.False(t, cluster.GetTaskLocks()[1].GetStale())
+}
+
+func TestServerTaskControl(t *testing.T) {
+	ctx := context.Background()
+
+	c, err := controller.New(ctx, config.New(), "test")
+	require.NoError(t, err)
+
+	c.RegisterTasks(schemas.TaskTypeGarbageCollectMetrics, func() error { return nil })
+	c.RegisterTasks(schemas.TaskTypePullMetrics, func() error { return nil })
+
+	client := newTestClient(t, &c)
+
+	_, err = client.PauseTaskScheduling(ctx, &pb.TaskRequest{TaskType: string(schemas.TaskTypeGarbageCollectMetrics)})
+	require.NoError(t, err)
+
+	tasks, err := client.ListTasks(ctx, &pb.Empty{})
+	require.NoError(t, err)
+
+	paused := map[string]bool{}
+	for _, task := range tasks.GetTasks() {
+		paused[task.GetTaskType()] = task.GetPaused()
+	}
+
+	assert.Equal(t, map[string]bool{
+		string(schemas.TaskTypeGarbageCollectMetrics): true,
+		string(schemas.TaskTypePullMetrics):           false,
+	}, paused)
+
+	_, err = client.ResumeTaskScheduling(ctx, &pb.TaskRequest{TaskType: string(schemas.TaskTypeGarbageCollectMetrics)})
+	require.NoError(t, err)
+
+	status, err := c.Store.TaskSchedulingStatus(ctx, schemas.TaskTypeGarbageCollectMetrics)
+	require.NoError(t, err)
+	assert.False(t, status.Paused)
+
+	_, err = client.PauseTaskScheduling(ctx, &pb.TaskRequest{TaskType: "foo"})
+	assert.Equal(t, codes.NotFound, grpcstatus.Code(err))
+
+	_, err = client.TriggerTask(ctx, &pb.TaskRequest{TaskType: "foo"})
+	assert.Equal(t, codes.NotFound, grpcstatus.Code(err))
+
+	// The lock is already held, the task does not get scheduled twice
+	_, err = c.Store.QueueTask(ctx, schemas.TaskTypeGarbageCollectMetrics, "_", c.UUID.String())
+	require.NoError(t, err)
+
+	res, err := client.TriggerTask(ctx, &pb.TaskRequest{TaskType: string(schemas.TaskTypeGarbageCollectMetrics)})
+	require.NoError(t, err)
+	assert.Equal(t, controller.TaskSkippedReasonAlreadyQueued, res.GetStatus())
+}
+
+func TestServerTaskLocks(t *testing.T) {
+	ctx := context.Background()
+
+	c, err := controller.New(ctx, config.New(), "test")
+	require.NoError(t, err)
+
+	client := newTestClient(t, &c)
+
+	_, err = c.Store.QueueTask(ctx, "foo", "_", c.UUID.String())
+	require.NoError(t, err)
+	_, err = c.Store.QueueTask(ctx, "bar", "_", "dead")
+	require.NoError(t, err)
+
+	released, err := client.ReleaseStaleTaskLocks(ctx, &pb.Empty{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), released.GetCount())
+
+	locks, err := c.Store.TaskLocks(ctx)
+	require.NoError(t, err)
+	require.Len(t, locks, 1)
+	assert.Equal(t, schemas.TaskType("foo"), locks[0].TaskType)
+
+	released, err = client.PurgeTaskQueue(ctx, &pb.Empty{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), released.GetCount())
+
+	count, err := c.Store.CurrentlyQueuedTasksCount(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	// Released locks are not accounted as executed tasks
+	executed, err := c.Store.ExecutedTasksCount(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, executed)
+}
+
+func TestServerDeleteMetric(t *testing.T) {
+	ctx := context.Background()
+
+	c, err := controller.New(ctx, config.New(), "test")
+	require.NoError(t, err)
+
+	m := schemas.Metric{Kind: 100, Labels: map[string]string{"foo": "bar"}, Value: 1}
+	require.NoError(t, c.Store.SetMetric(ctx, m))
+
+	client := newTestClient(t, &c)
+
+	_, err = client.DeleteMetric(ctx, &pb.DeleteMetricRequest{Key: string(m.Key())})
+	require.NoError(t, err)
+
+	exists, err := c.Store.MetricExists(ctx, m.Key())
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	_, err = client.DeleteMetric(ctx, &pb.DeleteMetricRequest{Key: string(m.Key())})
+	assert.Equal(t, codes.NotFound, grpcstatus.Code(err))
+}
@@ -0,0 +1,187 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gopkg.in/yaml.v3"
+
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/config"
+	pb "github.com/xnok/mend-renovate-ce-ee-exporter/pkg/monitor/protobuf"
+)
+
+// List of the formats in which a snapshot can be written.
+const (
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+	OutputTable = "table"
+)
+
+// Snapshot is a point in time view of the exporter, meant to be consumed by scripts.
+type Snapshot struct {
+	Telemetry SnapshotTelemetry      `json:"telemetry" yaml:"telemetry"`
+	Config    map[string]interface{} `json:"config" yaml:"config"`
+
+	// Problems lists what is wrong with the exporter, it is empty when healthy
+	Problems []string `json:"problems" yaml:"problems"`
+}
+
+// SnapshotTelemetry ..
+type SnapshotTelemetry struct {
+	TasksBufferUsage   float64    `json:"tasks_buffer_usage" yaml:"tasks_buffer_usage"`
+	TasksExecutedCount uint64     `json:"tasks_executed_count" yaml:"tasks_executed_count"`
+	MetricsCount       int64      `json:"metrics_count" yaml:"metrics_count"`
+	LastPull           *time.Time `json:"last_pull,omitempty" yaml:"last_pull,omitempty"`
+	NextPull           *time.Time `json:"next_pull,omitempty" yaml:"next_pull,omitempty"`
+	LastGC             *time.Time `json:"last_gc,omitempty" yaml:"last_gc,omitempty"`
+	NextGC             *time.Time `json:"next_gc,omitempty" yaml:"next_gc,omitempty"`
+}
+
+// SnapshotThresholds defines when the telemetry indicates trouble.
+type SnapshotThresholds struct {
+	// MaxTasksBufferUsage is the ratio of the tasks buffer from which it is considered full
+	MaxTasksBufferUsage float64
+}
+
+// NewSnapshot builds a snapshot out of the telemetry and the config returned by the server,
+// the last pull is considered stale using the health settings of the config.
+func NewSnapshot(t *pb.Telemetry, c *pb.Config, thresholds SnapshotThresholds, now time.Time) (s Snapshot, err error) {
+	var cfg config.Config
+
+	// The values of the config returned by the server have already been expanded
+	if cfg, err = config.Parse(config.FormatYAML, []byte(c.GetContent()), config.AllowUnknownKeys(), config.KeepEnvReferences()); err != nil {
+		return s, fmt.Errorf("parsing the config: %w", err)
+	}
+
+	if err = yaml.Unmarshal([]byte(c.GetContent()), &s.Config); err != nil {
+		return s, fmt.Errorf("parsing the config: %w", err)
+	}
+
+	s.Telemetry = SnapshotTelemetry{
+		TasksBufferUsage:   t.GetTasksBufferUsage(),
+		TasksExecutedCount: t.GetTasksExecutedCount(),
+		MetricsCount:       t.GetMetrics().GetCount(),
+		LastPull:           snapshotTime(t.GetMetrics().GetLastPull()),
+		NextPull:           snapshotTime(t.GetMetrics().GetNextPull()),
+		LastGC:             snapshotTime(t.GetMetrics().GetLastGc()),
+		NextGC:             snapshotTime(t.GetMetrics().GetNextGc()),
+	}
+
+	s.Problems = []string{}
+
+	if thresholds.MaxTasksBufferUsage > 0 && s.Telemetry.TasksBufferUsage >= thresholds.MaxTasksBufferUsage {
+		s.Problems = append(s.Problems, fmt.Sprintf("tasks buffer usage is at %.0f%%", s.Telemetry.TasksBufferUsage*100))
+	}
+
+	pull := cfg.Pull.Metrics
+	maxIntervals := cfg.Server.Health.MaxPullIntervalsWithoutSuccess
+
+	if pull.Scheduled && maxIntervals > 0 {
+		maxAge := time.Duration(maxIntervals*pull.IntervalSeconds) * time.Second
+
+		switch {
+		case s.Telemetry.LastPull == nil:
+			s.Problems = append(s.Problems, "metrics have never been pulled")
+		case now.Sub(*s.Telemetry.LastPull) > maxAge:
+			s.Problems = append(s.Problems, fmt.Sprintf("last pull is stale, %s ago (max %s)", now.Sub(*s.Telemetry.LastPull).Round(time.Second), maxAge))
+		}
+	}
+
+	return
+}
+
+// snapshotTime returns nil for unset times, in order to omit them.
+func snapshotTime(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil || ts.AsTime().IsZero() {
+		return nil
+	}
+
+	t := ts.AsTime()
+
+	return &t
+}
+
+// Healthy returns whether no problem was found.
+func (s Snapshot) Healthy() bool {
+	return len(s.Problems) == 0
+}
+
+// Write outputs the snapshot in the given format.
+func (s Snapshot) Write(w io.Writer, format string) error {
+	switch format {
+	case OutputJSON:
+		return json.NewEncoder(w).Encode(s)
+	case OutputYAML:
+		enc := yaml.NewEncoder(w)
+		if err := enc.Encode(s); err != nil {
+			return err
+		}
+
+		return enc.Close()
+	case OutputTable:
+		return s.writeTable(w)
+	default:
+		return fmt.Errorf("unsupported output format '%s', expected one of %s, %s or %s", format, OutputJSON, OutputYAML, OutputTable)
+	}
+}
+
+func (s Snapshot) writeTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	formatTime := func(t *time.Time) string {
+		if t == nil {
+			return "N/A"
+		}
+
+		return t.Format(time.RFC3339)
+	}
+
+	fmt.Fprintln(tw, "KEY\tVALUE")
+	fmt.Fprintf(tw, "telemetry.tasks_buffer_usage\t%.0f%%\n", s.Telemetry.TasksBufferUsage*100)
+	fmt.Fprintf(tw, "telemetry.tasks_executed_count\t%d\n", s.Telemetry.TasksExecutedCount)
+	fmt.Fprintf(tw, "telemetry.metrics_count\t%d\n", s.Telemetry.MetricsCount)
+	fmt.Fprintf(tw, "telemetry.last_pull\t%s\n", formatTime(s.Telemetry.LastPull))
+	fmt.Fprintf(tw, "telemetry.next_pull\t%s\n", formatTime(s.Telemetry.NextPull))
+	fmt.Fprintf(tw, "telemetry.last_gc\t%s\n", formatTime(s.Telemetry.LastGC))
+	fmt.Fprintf(tw, "telemetry.next_gc\t%s\n", formatTime(s.Telemetry.NextGC))
+
+	for _, row := range flattenConfig("config", s.Config) {
+		fmt.Fprintln(tw, row)
+	}
+
+	status := "healthy"
+	if !s.Healthy() {
+		status = strings.Join(s.Problems, "; ")
+	}
+
+	fmt.Fprintf(tw, "status\t%s\n", status)
+
+	return tw.Flush()
+}
+
+// flattenConfig returns a sorted list of tab separated key/value rows, the keys being dot separated paths.
+func flattenConfig(prefix string, v interface{}) (rows []string) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			rows = append(rows, flattenConfig(prefix+"."+k, v[k])...)
+		}
+	default:
+		rows = append(rows, fmt.Sprintf("%s\t%v", prefix, v))
+	}
+
+	return
+}
@@ -0,0 +1,112 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/config"
+	pb "github.com/xnok/mend-renovate-ce-ee-exporter/pkg/monitor/protobuf"
+)
+
+func TestNewSnapshot(t *testing.T) {
+	now := time.Now()
+
+	cfg := config.New()
+	cfg.Pull.Metrics.IntervalSeconds = 10
+	cfg.Server.Health.MaxPullIntervalsWithoutSuccess = 3
+	pbCfg := &pb.Config{Content: cfg.ToYAML()}
+
+	thresholds := SnapshotThresholds{MaxTasksBufferUsage: 1}
+
+	tests := []struct {
+		name      string
+		telemetry *pb.Telemetry
+		problems  []string
+	}{
+		{
+			name: "healthy",
+			telemetry: &pb.Telemetry{
+				TasksBufferUsage: 0.5,
+				Metrics:          &pb.Entity{LastPull: timestamppb.New(now.Add(-20 * time.Second))},
+			},
+			problems: []string{},
+		},
+		{
+			name: "full buffer",
+			telemetry: &pb.Telemetry{
+				TasksBufferUsage: 1,
+				Metrics:          &pb.Entity{LastPull: timestamppb.New(now)},
+			},
+			problems: []string{"tasks buffer usage is at 100%"},
+		},
+		{
+			name: "stale last pull",
+			telemetry: &pb.Telemetry{
+				Metrics: &pb.Entity{LastPull: timestamppb.New(now.Add(-time.Minute))},
+			},
+			problems: []string{"last pull is stale, 1m0s ago (max 30s)"},
+		},
+		{
+			name: "never pulled",
+			telemetry: &pb.Telemetry{
+				Metrics: &pb.Entity{LastPull: timestamppb.New(time.Time{})},
+			},
+			problems: []string{"metrics have never been pulled"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewSnapshot(tt.telemetry, pbCfg, thresholds, now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.problems, s.Problems)
+			assert.Equal(t, len(tt.problems) == 0, s.Healthy())
+		})
+	}
+}
+
+func TestNewSnapshotEnvReferences(t *testing.T) {
+	// The value was written $${NOT_DEFINED} in the config file of the server
+	cfg := config.New()
+	cfg.Renovate.BranchPrefix = "${NOT_DEFINED}/"
+
+	s, err := NewSnapshot(&pb.Telemetry{}, &pb.Config{Content: cfg.ToYAML()}, SnapshotThresholds{}, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, "${NOT_DEFINED}/", s.Config["renovate"].(map[string]interface{})["branch_prefix"])
+}
+
+func TestSnapshotWrite(t *testing.T) {
+	s, err := NewSnapshot(
+		&pb.Telemetry{TasksExecutedCount: 3, Metrics: &pb.Entity{Count: 2}},
+		&pb.Config{Content: config.New().ToYAML()},
+		SnapshotThresholds{},
+		time.Now(),
+	)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+
+	require.NoError(t, s.Write(&out, OutputJSON))
+
+	decoded := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, float64(3), decoded["telemetry"].(map[string]interface{})["tasks_executed_count"])
+	assert.NotContains(t, decoded["telemetry"], "last_pull")
+
+	out.Reset()
+	require.NoError(t, s.Write(&out, OutputYAML))
+	assert.Contains(t, out.String(), "metrics_count: 2\n")
+
+	out.Reset()
+	require.NoError(t, s.Write(&out, OutputTable))
+	assert.Regexp(t, `telemetry.metrics_count\s+2\n`, out.String())
+	assert.Regexp(t, `config.server.listen_address\s+:8080\n`, out.String())
+
+	assert.Error(t, s.Write(&out, "xml"))
+}
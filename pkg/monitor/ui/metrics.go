@@ -0,0 +1,297 @@
+package ui
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/monitor"
+	pb "github.com/xnok/mend-renovate-ce-ee-exporter/pkg/monitor/protobuf"
+)
+
+// filterDebounceDelay is how long the filter has to remain unchanged before the metrics get refreshed.
+const filterDebounceDelay = 300 * time.Millisecond
+
+type metricsColumn int
+
+const (
+	metricsColumnName metricsColumn = iota
+	metricsColumnLabels
+	metricsColumnValue
+	metricsColumnUpdated
+)
+
+var metricsColumnTitles = [...]string{
+	metricsColumnName:    "Name",
+	metricsColumnLabels:  "Labels",
+	metricsColumnValue:   "Value",
+	metricsColumnUpdated: "Updated",
+}
+
+// metricsBrowser lists the metrics held in the store, they are filtered
+// by the server and sorted locally.
+type metricsBrowser struct {
+	client     *monitor.Client
+	filter     textinput.Model
+	table      table.Model
+	metrics    []*pb.Metric
+	widths     []int
+	err        error
+	sortColumn metricsColumn
+	sortDesc   bool
+
+	// filterVersion is incremented upon each change of the filter, in order to debounce the refreshes
+	filterVersion int
+}
+
+func newMetricsBrowser(client *monitor.Client) *metricsBrowser {
+	filter := textinput.New()
+	filter.Prompt = " filter: "
+	filter.Placeholder = "name, label=value or key"
+
+	b := &metricsBrowser{
+		client: client,
+		filter: filter,
+		table:  table.New(table.WithFocused(true)),
+	}
+
+	b.setSize(80, 20)
+
+	return b
+}
+
+// setSize fits the table within the pane, the labels and the names share the space left by the other columns.
+func (b *metricsBrowser) setSize(width, height int) {
+	// Each column is padded on both sides
+	shared := max(20, width-2*len(metricsColumnTitles)-16-16)
+
+	b.widths = []int{shared / 2, shared - shared/2, 16, 16}
+	b.table.SetColumns(b.columns())
+	b.table.SetWidth(width)
+	// The filter, the selected key, the help and their margins
+	b.table.SetHeight(max(1, height-6))
+}
+
+func (b *metricsBrowser) columns() (columns []table.Column) {
+	for i, title := range metricsColumnTitles {
+		if metricsColumn(i) == b.sortColumn {
+			if b.sortDesc {
+				title += " ▼"
+			} else {
+				title += " ▲"
+			}
+		}
+
+		columns = append(columns, table.Column{Title: title, Width: b.widths[i]})
+	}
+
+	return
+}
+
+// metricsListedMsg holds the outcome of the listing of the metrics matching the filter.
+type metricsListedMsg struct {
+	filter  string
+	metrics []*pb.Metric
+	err     error
+}
+
+// filterChangedMsg is sent once the filter has not changed for the debounce delay.
+type filterChangedMsg struct {
+	version int
+}
+
+// refresh returns the command fetching the metrics matching the filter, its outcome is then handled by update.
+func (b *metricsBrowser) refresh() tea.Cmd {
+	client, filter := b.client, b.filter.Value()
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), rpcTimeout)
+		defer cancel()
+
+		res, err := client.ListMetrics(ctx, &pb.ListMetricsRequest{Filter: filter})
+
+		return metricsListedMsg{filter: filter, metrics: res.GetMetrics(), err: err}
+	}
+}
+
+// update displays the listed metrics, the selected metric remains so if it still matches.
+func (b *metricsBrowser) update(msg metricsListedMsg) {
+	// The filter changed since, a new listing is on its way
+	if msg.filter != b.filter.Value() {
+		return
+	}
+
+	if b.err = msg.err; msg.err != nil {
+		return
+	}
+
+	b.metrics = msg.metrics
+	b.sortRows()
+}
+
+// filterChanged returns the command refreshing the metrics if the filter did not change in the meantime.
+func (b *metricsBrowser) filterChanged(msg filterChangedMsg) tea.Cmd {
+	if msg.version != b.filterVersion {
+		return nil
+	}
+
+	return b.refresh()
+}
+
+func (b *metricsBrowser) sortRows() {
+	selectedKey := b.selectedKey()
+
+	sort.SliceStable(b.metrics, func(i, j int) bool {
+		if b.sortDesc {
+			return metricsLess(b.metrics[j], b.metrics[i], b.sortColumn)
+		}
+
+		return metricsLess(b.metrics[i], b.metrics[j], b.sortColumn)
+	})
+
+	rows := make([]table.Row, 0, len(b.metrics))
+	cursor := 0
+
+	for i, m := range b.metrics {
+		if m.GetKey() == selectedKey {
+			cursor = i
+		}
+
+		rows = append(rows, table.Row{
+			m.GetName(),
+			formatLabels(m.GetLabels()),
+			strconv.FormatFloat(m.GetValue(), 'g', -1, 64),
+			prettyTimeago(m.GetUpdatedAt().AsTime()),
+		})
+	}
+
+	b.table.SetColumns(b.columns())
+	b.table.SetRows(rows)
+	b.table.SetCursor(cursor)
+}
+
+func metricsLess(a, b *pb.Metric, column metricsColumn) bool {
+	switch column {
+	case metricsColumnLabels:
+		return formatLabels(a.GetLabels()) < formatLabels(b.GetLabels())
+	case metricsColumnValue:
+		return a.GetValue() < b.GetValue()
+	case metricsColumnUpdated:
+		return a.GetUpdatedAt().AsTime().Before(b.GetUpdatedAt().AsTime())
+	default:
+		return a.GetName() < b.GetName()
+	}
+}
+
+func formatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for name, value := range labels {
+		pairs = append(pairs, name+"="+value)
+	}
+
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ", ")
+}
+
+func (b *metricsBrowser) selectedKey() string {
+	if cursor := b.table.Cursor(); cursor >= 0 && cursor < len(b.metrics) {
+		return b.metrics[cursor].GetKey()
+	}
+
+	return ""
+}
+
+// handleKey processes the keys used by the browser, handled is false for
+// the ones which should be processed by the rest of the UI.
+func (b *metricsBrowser) handleKey(msg tea.KeyMsg) (handled bool, cmd tea.Cmd) {
+	if msg.Type == tea.KeyCtrlC {
+		return false, nil
+	}
+
+	if b.filter.Focused() {
+		switch msg.Type {
+		case tea.KeyEnter, tea.KeyEsc:
+			b.filter.Blur()
+			b.table.Focus()
+
+			return true, nil
+		}
+
+		previous := b.filter.Value()
+		b.filter, cmd = b.filter.Update(msg)
+
+		if b.filter.Value() != previous {
+			b.filterVersion++
+			version := b.filterVersion
+
+			cmd = tea.Batch(cmd, tea.Tick(filterDebounceDelay, func(time.Time) tea.Msg {
+				return filterChangedMsg{version: version}
+			}))
+		}
+
+		return true, cmd
+	}
+
+	switch msg.String() {
+	case "/":
+		b.table.Blur()
+
+		return true, b.filter.Focus()
+	case "s":
+		b.sortColumn = (b.sortColumn + 1) % metricsColumn(len(metricsColumnTitles))
+		b.sortRows()
+
+		return true, nil
+	case "r":
+		b.sortDesc = !b.sortDesc
+		b.sortRows()
+
+		return true, nil
+	case "x":
+		key := b.selectedKey()
+		if key == "" {
+			return true, nil
+		}
+
+		return true, confirm("Delete the metric "+key+"?", func(ctx context.Context) (string, error) {
+			_, err := b.client.DeleteMetric(ctx, &pb.DeleteMetricRequest{Key: key})
+
+			return "metric " + key + " deleted", err
+		})
+	}
+
+	switch msg.Type {
+	case tea.KeyLeft, tea.KeyRight, tea.KeyEsc:
+		return false, nil
+	}
+
+	b.table, cmd = b.table.Update(msg)
+
+	return true, cmd
+}
+
+func (b *metricsBrowser) view() string {
+	selected := " key: " + dataStyle.SetString(b.selectedKey()).String()
+	if b.err != nil {
+		selected = errorStyle.Render(" listing metrics: " + b.err.Error())
+	}
+
+	return strings.Join(
+		[]string{
+			"",
+			b.filter.View(),
+			"",
+			b.table.View(),
+			"",
+			selected,
+			helpStyle.Render(" / filter • enter apply • s sort column • r reverse order • ↑/↓ select • x delete"),
+		}, "\n",
+	)
+}
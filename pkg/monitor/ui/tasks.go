@@ -0,0 +1,162 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/monitor"
+	pb "github.com/xnok/mend-renovate-ce-ee-exporter/pkg/monitor/protobuf"
+)
+
+var selectedTaskStyle = lipgloss.NewStyle().
+	Bold(true).
+	Foreground(highlight)
+
+// tasksList lists the registered task types and exposes the actions controlling them.
+type tasksList struct {
+	client *monitor.Client
+	tasks  []*pb.Task
+	cursor int
+	err    error
+}
+
+func newTasksList(client *monitor.Client) *tasksList {
+	return &tasksList{
+		client: client,
+	}
+}
+
+// tasksListedMsg holds the outcome of the listing of the tasks.
+type tasksListedMsg struct {
+	tasks []*pb.Task
+	err   error
+}
+
+// refresh returns the command listing the tasks, its outcome is then handled by update.
+func (l *tasksList) refresh() tea.Cmd {
+	client := l.client
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), rpcTimeout)
+		defer cancel()
+
+		res, err := client.ListTasks(ctx, &pb.Empty{})
+
+		return tasksListedMsg{tasks: res.GetTasks(), err: err}
+	}
+}
+
+func (l *tasksList) update(msg tasksListedMsg) {
+	if l.err = msg.err; msg.err != nil {
+		return
+	}
+
+	l.tasks = msg.tasks
+	l.cursor = min(l.cursor, max(0, len(l.tasks)-1))
+}
+
+func (l *tasksList) selected() *pb.Task {
+	if l.cursor < len(l.tasks) {
+		return l.tasks[l.cursor]
+	}
+
+	return nil
+}
+
+// handleKey processes the keys used by the list, handled is false for
+// the ones which should be processed by the rest of the UI.
+func (l *tasksList) handleKey(msg tea.KeyMsg) (handled bool, cmd tea.Cmd) {
+	switch msg.String() {
+	case "up":
+		l.cursor = max(0, l.cursor-1)
+
+		return true, nil
+	case "down":
+		l.cursor = min(l.cursor+1, max(0, len(l.tasks)-1))
+
+		return true, nil
+	case "P":
+		return true, confirm("Purge the task queue?", func(ctx context.Context) (string, error) {
+			res, err := l.client.PurgeTaskQueue(ctx, &pb.Empty{})
+
+			return fmt.Sprintf("task queue purged, %d task locks released", res.GetCount()), err
+		})
+	case "L":
+		return true, confirm("Release the stale task locks?", func(ctx context.Context) (string, error) {
+			res, err := l.client.ReleaseStaleTaskLocks(ctx, &pb.Empty{})
+
+			return fmt.Sprintf("%d stale task locks released", res.GetCount()), err
+		})
+	}
+
+	task := l.selected()
+	if task == nil {
+		return false, nil
+	}
+
+	req := &pb.TaskRequest{TaskType: task.GetTaskType()}
+
+	switch msg.String() {
+	case "t":
+		return true, confirm("Trigger "+task.GetTaskType()+"?", func(ctx context.Context) (string, error) {
+			res, err := l.client.TriggerTask(ctx, req)
+
+			return task.GetTaskType() + ": " + res.GetStatus(), err
+		})
+	case "p":
+		if task.GetPaused() {
+			return true, confirm("Resume the scheduling of "+task.GetTaskType()+"?", func(ctx context.Context) (string, error) {
+				_, err := l.client.ResumeTaskScheduling(ctx, req)
+
+				return "scheduling of " + task.GetTaskType() + " resumed", err
+			})
+		}
+
+		return true, confirm("Pause the scheduling of "+task.GetTaskType()+"?", func(ctx context.Context) (string, error) {
+			_, err := l.client.PauseTaskScheduling(ctx, req)
+
+			return "scheduling of " + task.GetTaskType() + " paused", err
+		})
+	}
+
+	return false, nil
+}
+
+func (l *tasksList) view() string {
+	if l.err != nil {
+		return "\n" + errorStyle.Render(" listing tasks: "+l.err.Error())
+	}
+
+	rows := []string{""}
+
+	for i, task := range l.tasks {
+		name := fmt.Sprintf("   %-32s", task.GetTaskType())
+		if i == l.cursor {
+			name = selectedTaskStyle.Render(fmt.Sprintf(" ▸ %-32s", task.GetTaskType()))
+		}
+
+		state := dataStyle.SetString("scheduled").String()
+		if task.GetPaused() {
+			state = staleStyle.SetString("paused").String()
+		}
+
+		rows = append(
+			rows,
+			name+state+
+				"Last "+dataStyle.SetString(prettyTimeago(task.GetLastScheduled().AsTime())).String()+
+				"Next "+dataStyle.SetString(prettyTimeago(task.GetNextScheduled().AsTime())).String(),
+		)
+	}
+
+	return strings.Join(
+		append(
+			rows,
+			"",
+			helpStyle.Render(" ↑/↓ select • t trigger • p pause/resume scheduling • P purge queue • L release stale locks"),
+		), "\n",
+	)
+}
@@ -0,0 +1,163 @@
+package ui
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	pb "github.com/xnok/mend-renovate-ce-ee-exporter/pkg/monitor/protobuf"
+)
+
+// trendsWindow is the number of samples kept by each trend, the telemetry being
+// sampled every second and the Renovate status on each pull.
+const trendsWindow = 300
+
+// sparklineTicks are the characters used to draw the samples, from the lowest to the highest.
+var sparklineTicks = []rune("▁▂▃▄▅▆▇█")
+
+var sparklineStyle = lipgloss.NewStyle().
+	MarginLeft(1).
+	Foreground(highlight)
+
+// series is a rolling window of samples.
+type series struct {
+	samples []float64
+}
+
+func (s *series) push(v float64) {
+	s.samples = append(s.samples, v)
+	if overflow := len(s.samples) - trendsWindow; overflow > 0 {
+		s.samples = s.samples[overflow:]
+	}
+}
+
+// sparkline draws the most recent samples fitting within the width, scaled between 0 and the highest of them.
+func (s *series) sparkline(width int) string {
+	samples := s.samples
+	if len(samples) > width {
+		samples = samples[len(samples)-width:]
+	}
+
+	highest := highestSample(samples)
+
+	var b strings.Builder
+
+	for _, v := range samples {
+		tick := 0
+		if highest > 0 {
+			tick = int(v / highest * float64(len(sparklineTicks)-1))
+		}
+
+		b.WriteRune(sparklineTicks[tick])
+	}
+
+	return b.String()
+}
+
+func (s *series) last() float64 {
+	if len(s.samples) == 0 {
+		return 0
+	}
+
+	return s.samples[len(s.samples)-1]
+}
+
+func (s *series) highest() float64 {
+	return highestSample(s.samples)
+}
+
+func highestSample(samples []float64) (highest float64) {
+	for _, v := range samples {
+		highest = math.Max(highest, v)
+	}
+
+	return
+}
+
+// executedTasks is a reading of the executed tasks counter.
+type executedTasks struct {
+	at    time.Time
+	count uint64
+}
+
+// trends keeps a rolling window of the telemetry in order to show how it evolves.
+type trends struct {
+	queueLength    series
+	tasksPerMinute series
+	scrapeLatency  series
+
+	// executed holds the readings of the last minute, used to compute the tasks per minute
+	executed []executedTasks
+	pulledAt time.Time
+}
+
+// observeTelemetry samples the tasks executed over the last minute.
+func (t *trends) observeTelemetry(telemetry *pb.Telemetry, now time.Time) {
+	t.executed = append(t.executed, executedTasks{at: now, count: telemetry.GetTasksExecutedCount()})
+
+	// We keep the most recent reading which is at least a minute old, as the reference
+	for len(t.executed) > 1 && now.Sub(t.executed[1].at) >= time.Minute {
+		t.executed = t.executed[1:]
+	}
+
+	first, last := t.executed[0], t.executed[len(t.executed)-1]
+	if elapsed := last.at.Sub(first.at); elapsed > 0 && last.count >= first.count {
+		t.tasksPerMinute.push(float64(last.count-first.count) / elapsed.Minutes())
+	}
+}
+
+// observeRenovateStatus samples the Renovate status, once per pull.
+func (t *trends) observeRenovateStatus(s *pb.RenovateStatus) {
+	pulledAt := s.GetPulledAt().AsTime()
+	if s.GetPulledAt() == nil || pulledAt.IsZero() || pulledAt.Equal(t.pulledAt) {
+		return
+	}
+
+	t.pulledAt = pulledAt
+	t.queueLength.push(float64(s.GetQueueLength()))
+	t.scrapeLatency.push(s.GetScrapeLatency().AsDuration().Seconds())
+}
+
+func (t *trends) view(width int) string {
+	// The labels, the current values and their margins
+	width = max(10, width-50)
+
+	row := func(name string, s *series, format func(float64) string) string {
+		if len(s.samples) == 0 {
+			return " " + name + strings.Repeat(" ", 24-len(name)) + dataStyle.SetString("N/A").String() + "\n"
+		}
+
+		return lipgloss.JoinHorizontal(
+			lipgloss.Top,
+			" "+name+strings.Repeat(" ", 24-len(name)),
+			dataStyle.SetString(format(s.last())).String(),
+			"max "+format(s.highest()),
+			sparklineStyle.Render(s.sparkline(width)),
+			"\n",
+		)
+	}
+
+	count := func(v float64) string {
+		return fmt.Sprintf("%.0f", v)
+	}
+
+	rate := func(v float64) string {
+		return fmt.Sprintf("%.1f", v)
+	}
+
+	latency := func(v float64) string {
+		return (time.Duration(v * float64(time.Second))).Round(time.Millisecond).String()
+	}
+
+	return entityStyle.Render(
+		lipgloss.JoinVertical(
+			lipgloss.Left,
+			row("Renovate queue length", &t.queueLength, count),
+			row("Tasks per minute", &t.tasksPerMinute, rate),
+			row("Scrape latency", &t.scrapeLatency, latency),
+		),
+	)
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	log "github.com/sirupsen/logrus"
 	"github.com/xeonx/timeago"
+	"google.golang.org/grpc/status"
 
 	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/monitor"
 	pb "github.com/xnok/mend-renovate-ce-ee-exporter/pkg/monitor/protobuf"
@@ -22,6 +23,12 @@ import (
 
 const (
 	renderStyle string = "github.com/xnok/mend-renovate-ce-ee-exporter"
+
+	// rpcTimeout bounds the calls made to the exporter outside of the streams
+	rpcTimeout = 5 * time.Second
+
+	// refreshInterval is how often the lists displayed by the current tab get refreshed
+	refreshInterval = 5 * time.Second
 )
 
 type tab string
@@ -29,11 +36,19 @@ type tab string
 const (
 	tabTelemetry tab = "telemetry"
 	tabConfig    tab = "config"
+	tabRenovate  tab = "renovate"
+	tabMetrics   tab = "metrics"
+	tabCluster   tab = "cluster"
+	tabTasks     tab = "tasks"
 )
 
 var tabs = [...]tab{
 	tabTelemetry,
 	tabConfig,
+	tabRenovate,
+	tabMetrics,
+	tabCluster,
+	tabTasks,
 }
 
 var (
@@ -48,6 +63,16 @@ var (
 			Foreground(lipgloss.Color("#000000")).
 			Background(lipgloss.Color("#a9a9a9"))
 
+	staleStyle = dataStyle.Copy().
+			Foreground(lipgloss.Color("#FFFDF5")).
+			Background(lipgloss.Color("#d70000"))
+
+	helpStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "#B2B2B2", Dark: "#4A4A4A"})
+
+	errorStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#ff5f5f"))
+
 	// Tabs.
 
 	activeTabBorder = lipgloss.Border{
@@ -131,7 +156,84 @@ type model struct {
 	progress        *progress.Model
 	telemetry       *pb.Telemetry
 	telemetryStream chan *pb.Telemetry
+	renovateStatus  *pb.RenovateStatus
+	renovateStream  chan *pb.RenovateStatus
+	metrics         *metricsBrowser
+	cluster         *pb.Cluster
+	clusterStream   chan *pb.Cluster
+	tasks           *tasksList
+	trends          *trends
 	tabID           int
+
+	// pending is the action awaiting to be confirmed, its outcome is then reported by the notice
+	pending *confirmation
+	notice  string
+}
+
+// confirmation is an action altering the state of the exporter, it only runs once confirmed by the user.
+type confirmation struct {
+	prompt string
+	run    func(context.Context) (string, error)
+}
+
+// actionDoneMsg reports the outcome of a confirmed action.
+type actionDoneMsg struct {
+	notice string
+	err    error
+}
+
+// refreshMsg triggers the refresh of the lists displayed by the current tab.
+type refreshMsg struct{}
+
+func refreshTick() tea.Cmd {
+	return tea.Tick(refreshInterval, func(time.Time) tea.Msg {
+		return refreshMsg{}
+	})
+}
+
+// confirm asks the user to confirm the action before running it.
+func confirm(prompt string, run func(context.Context) (string, error)) tea.Cmd {
+	return func() tea.Msg {
+		return &confirmation{
+			prompt: prompt,
+			run:    run,
+		}
+	}
+}
+
+// resolve returns the command running the pending action if the key confirms it, it is cancelled otherwise.
+func (m *model) resolve(msg tea.KeyMsg) tea.Cmd {
+	c := m.pending
+	m.pending = nil
+
+	if msg.String() != "y" {
+		m.notice = "cancelled"
+
+		return nil
+	}
+
+	m.notice = "running.."
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), rpcTimeout)
+		defer cancel()
+
+		notice, err := c.run(ctx)
+
+		return actionDoneMsg{notice: notice, err: err}
+	}
+}
+
+// refresh returns the command refreshing the lists displayed by the current tab, if any.
+func (m *model) refresh() tea.Cmd {
+	switch tabs[m.tabID] {
+	case tabMetrics:
+		return m.metrics.refresh()
+	case tabTasks:
+		return m.tasks.refresh()
+	}
+
+	return nil
 }
 
 func (m *model) renderConfigViewport() string {
@@ -168,10 +270,139 @@ func (m *model) renderTelemetryViewport() string {
 			tasksBufferUsage,
 			tasksExecuted,
 			renderEntity("Metrics", m.telemetry.GetMetrics()),
+			m.trends.view(m.vp.Width),
+		}, "\n",
+	)
+}
+
+func (m *model) renderRenovateViewport() string {
+	if m.renovateStatus == nil {
+		return "\nloading data.."
+	}
+
+	if m.renovateStatus.GetPulledAt().AsTime().IsZero() {
+		return "\nno status pulled from Mend Renovate yet.."
+	}
+
+	s := m.renovateStatus
+	currentJob := "idle"
+
+	if j := s.GetCurrentJob(); j.GetRepository() != "" {
+		currentJob = fmt.Sprintf(
+			"%s (reason: %s, priority: %d, running for %s)",
+			j.GetRepository(),
+			j.GetReason(),
+			j.GetPriority(),
+			prettyDuration(s.GetCurrentJobStartedAt().AsTime()),
+		)
+	}
+
+	jobsInProgress := []string{dataStyle.SetString(strconv.Itoa(len(s.GetJobsInProgress()))).String() + "\n"}
+	for _, j := range s.GetJobsInProgress() {
+		jobsInProgress = append(jobsInProgress, j.GetRepository()+" started "+prettyTimeago(j.GetStartedAt().AsTime())+"\n")
+	}
+
+	return strings.Join(
+		[]string{
+			"",
+			" Pulled                  " + dataStyle.SetString(prettyTimeago(s.GetPulledAt().AsTime())).String() + "\n",
+			" Queue length            " + dataStyle.SetString(strconv.Itoa(int(s.GetQueueLength()))).String() + "\n",
+			" Current job             " + dataStyle.SetString(currentJob).String() + "\n",
+			entityStyle.Render(
+				lipgloss.JoinHorizontal(
+					lipgloss.Top,
+					" Jobs in progress        ",
+					lipgloss.JoinVertical(lipgloss.Left, jobsInProgress...),
+				),
+			),
+			entityStyle.Render(
+				lipgloss.JoinHorizontal(
+					lipgloss.Top,
+					" Scheduler               ",
+					lipgloss.JoinVertical(
+						lipgloss.Left,
+						"Cron      "+dataStyle.SetString(s.GetSchedulerCron()).String()+"\n",
+						"Platform  "+dataStyle.SetString(s.GetSchedulerPlatform()).String()+"\n",
+					),
+				),
+			),
+			" Last webhook            " + dataStyle.SetString(prettyTimeago(s.GetLastWebhookReceivedAt().AsTime())).String() + "\n",
+		}, "\n",
+	)
+}
+
+func (m *model) renderClusterViewport() string {
+	if m.cluster == nil {
+		return "\nloading data.."
+	}
+
+	replicas := []string{}
+	for _, r := range m.cluster.GetReplicas() {
+		role := "follower"
+		if r.GetLeader() {
+			role = "leader"
+		}
+
+		name := shortUUID(r.GetUuid()) + " @ " + r.GetHostname()
+		if r.GetCurrent() {
+			name += " (connected)"
+		}
+
+		lastTask := "none"
+		if r.GetLastTaskType() != "" {
+			lastTask = r.GetLastTaskType() + " " + prettyTimeago(r.GetLastTaskAt().AsTime())
+		}
+
+		replicas = append(
+			replicas,
+			name+"\n",
+			"  Version    "+dataStyle.SetString(r.GetVersion()).String()+"\n",
+			"  Role       "+dataStyle.SetString(role).String()+"\n",
+			"  Started    "+dataStyle.SetString(prettyTimeago(r.GetStartedAt().AsTime())).String()+"\n",
+			"  Last task  "+dataStyle.SetString(lastTask).String()+"\n",
+		)
+	}
+
+	locks := []string{dataStyle.SetString(strconv.Itoa(len(m.cluster.GetTaskLocks()))).String() + "\n"}
+	for _, l := range m.cluster.GetTaskLocks() {
+		owner := dataStyle.SetString(shortUUID(l.GetOwner())).String()
+		if l.GetStale() {
+			owner = staleStyle.SetString(shortUUID(l.GetOwner()) + " (stale)").String()
+		}
+
+		locks = append(locks, l.GetTaskType()+":"+l.GetUniqueId()+" held by "+owner+"\n")
+	}
+
+	return strings.Join(
+		[]string{
+			"",
+			entityStyle.Render(
+				lipgloss.JoinHorizontal(
+					lipgloss.Top,
+					" Replicas                ",
+					lipgloss.JoinVertical(lipgloss.Left, replicas...),
+				),
+			),
+			entityStyle.Render(
+				lipgloss.JoinHorizontal(
+					lipgloss.Top,
+					" Task locks              ",
+					lipgloss.JoinVertical(lipgloss.Left, locks...),
+				),
+			),
 		}, "\n",
 	)
 }
 
+// shortUUID keeps the first block of the UUID, which is enough to tell the replicas apart.
+func shortUUID(uuid string) string {
+	if i := strings.Index(uuid, "-"); i > 0 {
+		return uuid[:i]
+	}
+
+	return uuid
+}
+
 func renderEntity(name string, e *pb.Entity) string {
 	return entityStyle.Render(
 		lipgloss.JoinHorizontal(
@@ -198,17 +429,31 @@ func prettyTimeago(t time.Time) string {
 	return timeago.English.Format(t)
 }
 
-func newModel(version string, endpoint *url.URL) (m *model) {
+func prettyDuration(since time.Time) string {
+	if since.IsZero() {
+		return "N/A"
+	}
+
+	return time.Since(since).Round(time.Second).String()
+}
+
+func newModel(version string, endpoint *url.URL, opts monitor.Options) (m *model) {
 	p := progress.NewModel(progress.WithScaledGradient("#80c904", "#ff9d5c"))
 
 	m = &model{
 		version:         version,
 		vp:              viewport.Model{},
 		telemetryStream: make(chan *pb.Telemetry),
+		renovateStream:  make(chan *pb.RenovateStatus),
+		clusterStream:   make(chan *pb.Cluster),
 		progress:        &p,
-		client:          monitor.NewClient(context.TODO(), endpoint),
+		trends:          &trends{},
+		client:          monitor.NewClient(context.TODO(), endpoint, opts),
 	}
 
+	m.metrics = newMetricsBrowser(m.client)
+	m.tasks = newTasksList(m.client)
+
 	return
 }
 
@@ -216,6 +461,11 @@ func (m *model) Init() tea.Cmd {
 	return tea.Batch(
 		m.streamTelemetry(context.TODO()),
 		waitForTelemetryUpdate(m.telemetryStream),
+		m.streamRenovateStatus(context.TODO()),
+		waitForRenovateStatusUpdate(m.renovateStream),
+		m.streamCluster(context.TODO()),
+		waitForClusterUpdate(m.clusterStream),
+		refreshTick(),
 	)
 }
 
@@ -225,10 +475,36 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.vp.Width = msg.Width
 		m.vp.Height = msg.Height - 4
 		m.progress.Width = msg.Width - 27
+		m.metrics.setSize(msg.Width, m.vp.Height)
 		m.setPaneContent()
 
+		return m, nil
+	case *confirmation:
+		m.pending = msg
+
 		return m, nil
 	case tea.KeyMsg:
+		if m.pending != nil {
+			return m, m.resolve(msg)
+		}
+
+		m.notice = ""
+
+		switch tabs[m.tabID] {
+		case tabMetrics:
+			if handled, cmd := m.metrics.handleKey(msg); handled {
+				m.vp.SetContent(m.metrics.view())
+
+				return m, cmd
+			}
+		case tabTasks:
+			if handled, cmd := m.tasks.handleKey(msg); handled {
+				m.vp.SetContent(m.tasks.view())
+
+				return m, cmd
+			}
+		}
+
 		switch msg.Type {
 		case tea.KeyCtrlC, tea.KeyEsc:
 			return m, tea.Quit
@@ -236,6 +512,8 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.tabID > 0 {
 				m.tabID--
 				m.setPaneContent()
+
+				return m, m.refresh()
 			}
 
 			return m, nil
@@ -243,6 +521,8 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.tabID < len(tabs)-1 {
 				m.tabID++
 				m.setPaneContent()
+
+				return m, m.refresh()
 			}
 
 			return m, nil
@@ -252,11 +532,44 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			return m, cmd
 		}
+	case actionDoneMsg:
+		m.notice = msg.notice
+		if msg.err != nil {
+			m.notice = errorStyle.Render(status.Convert(msg.err).Message())
+		}
+
+		return m, m.refresh()
+	case refreshMsg:
+		return m, tea.Batch(m.refresh(), refreshTick())
+	case metricsListedMsg:
+		m.metrics.update(msg)
+		m.setPaneContent()
+
+		return m, nil
+	case filterChangedMsg:
+		return m, m.metrics.filterChanged(msg)
+	case tasksListedMsg:
+		m.tasks.update(msg)
+		m.setPaneContent()
+
+		return m, nil
 	case *pb.Telemetry:
 		m.telemetry = msg
+		m.trends.observeTelemetry(msg, time.Now())
 		m.setPaneContent()
 
 		return m, waitForTelemetryUpdate(m.telemetryStream)
+	case *pb.RenovateStatus:
+		m.renovateStatus = msg
+		m.trends.observeRenovateStatus(msg)
+		m.setPaneContent()
+
+		return m, waitForRenovateStatusUpdate(m.renovateStream)
+	case *pb.Cluster:
+		m.cluster = msg
+		m.setPaneContent()
+
+		return m, waitForClusterUpdate(m.clusterStream)
 	}
 
 	return m, nil
@@ -295,7 +608,7 @@ func (m *model) View() string {
 			statusStyle.Render(renderStyle),
 			statusText.Copy().
 				Width(max(0, m.vp.Width-(55+len(m.version)))).
-				Render(""),
+				Render(m.statusMessage()),
 			versionStyle.Render(m.version),
 		)
 
@@ -305,6 +618,15 @@ func (m *model) View() string {
 	return docStyle.Render(doc.String())
 }
 
+// statusMessage returns the prompt of the pending action or the outcome of the last one.
+func (m *model) statusMessage() string {
+	if m.pending != nil {
+		return m.pending.prompt + " (y/n)"
+	}
+
+	return m.notice
+}
+
 func (m *model) streamTelemetry(ctx context.Context) tea.Cmd {
 	c, err := m.client.GetTelemetry(ctx, &pb.Empty{})
 	if err != nil {
@@ -331,10 +653,62 @@ func waitForTelemetryUpdate(t chan *pb.Telemetry) tea.Cmd {
 	}
 }
 
+func (m *model) streamRenovateStatus(ctx context.Context) tea.Cmd {
+	c, err := m.client.GetRenovateStatus(ctx, &pb.Empty{})
+	if err != nil {
+		log.WithError(err).Fatal()
+	}
+
+	go func(m *model) {
+		for {
+			status, err := c.Recv()
+			if err != nil {
+				log.WithError(err).Fatal()
+			}
+
+			m.renovateStream <- status
+		}
+	}(m)
+
+	return nil
+}
+
+func waitForRenovateStatusUpdate(s chan *pb.RenovateStatus) tea.Cmd {
+	return func() tea.Msg {
+		return <-s
+	}
+}
+
+func (m *model) streamCluster(ctx context.Context) tea.Cmd {
+	c, err := m.client.GetCluster(ctx, &pb.Empty{})
+	if err != nil {
+		log.WithError(err).Fatal()
+	}
+
+	go func(m *model) {
+		for {
+			cluster, err := c.Recv()
+			if err != nil {
+				log.WithError(err).Fatal()
+			}
+
+			m.clusterStream <- cluster
+		}
+	}(m)
+
+	return nil
+}
+
+func waitForClusterUpdate(c chan *pb.Cluster) tea.Cmd {
+	return func() tea.Msg {
+		return <-c
+	}
+}
+
 // Start ..
-func Start(version string, listenerAddress *url.URL) {
+func Start(version string, listenerAddress *url.URL, opts monitor.Options) {
 	if _, err := tea.NewProgram(
-		newModel(version, listenerAddress),
+		newModel(version, listenerAddress, opts),
 		tea.WithAltScreen(),
 	).Run(); err != nil {
 		fmt.Println("Error running program:", err)
@@ -348,5 +722,13 @@ func (m *model) setPaneContent() {
 		m.vp.SetContent(m.renderTelemetryViewport())
 	case tabConfig:
 		m.vp.SetContent(m.renderConfigViewport())
+	case tabRenovate:
+		m.vp.SetContent(m.renderRenovateViewport())
+	case tabMetrics:
+		m.vp.SetContent(m.metrics.view())
+	case tabCluster:
+		m.vp.SetContent(m.renderClusterViewport())
+	case tabTasks:
+		m.vp.SetContent(m.tasks.view())
 	}
 }
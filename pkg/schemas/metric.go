@@ -2,7 +2,10 @@ package schemas
 
 import (
 	"hash/crc32"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -15,6 +18,9 @@ type Metric struct {
 	Kind   MetricKind
 	Labels prometheus.Labels
 	Value  float64
+
+	// UpdatedAt is set by the store whenever the metric gets written
+	UpdatedAt time.Time
 }
 
 // MetricKey ..
@@ -29,5 +35,19 @@ type Metrics map[MetricKey]Metric
 func (m Metric) Key() MetricKey {
 	key := strconv.Itoa(int(m.Kind))
 
+	// Metrics of the same kind are told apart using their labels
+	if len(m.Labels) > 0 {
+		names := make([]string, 0, len(m.Labels))
+		for name := range m.Labels {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			key += strings.Join([]string{"", name, m.Labels[name]}, "\x00")
+		}
+	}
+
 	return MetricKey(strconv.Itoa(int(crc32.ChecksumIEEE([]byte(key)))))
 }
@@ -0,0 +1,28 @@
+package schemas
+
+import (
+	"time"
+)
+
+// Replica describes a running exporter process.
+type Replica struct {
+	UUID      string
+	Version   string
+	Hostname  string
+	StartedAt time.Time
+	Leader    bool
+
+	// LastTaskType and LastTaskAt describe the last task executed by the process
+	LastTaskType TaskType
+	LastTaskAt   time.Time
+
+	// RunningTasks lists the types of the tasks being executed by the process
+	RunningTasks []TaskType
+}
+
+// TaskLock represents a queued task and the process which queued it.
+type TaskLock struct {
+	TaskType TaskType
+	UniqueID string
+	Owner    string
+}
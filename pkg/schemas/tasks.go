@@ -12,6 +12,8 @@ const (
 	TaskTypePullMetrics TaskType = "PullMetrics"
 	// TaskTypeGarbageCollectMetrics ..
 	TaskTypeGarbageCollectMetrics TaskType = "GarbageCollectMetrics"
+	// TaskTypeRelayWebhookDeliveries ..
+	TaskTypeRelayWebhookDeliveries TaskType = "RelayWebhookDeliveries"
 )
 
 // Tasks can be used to keep track of tasks.
@@ -21,4 +23,15 @@ type Tasks map[TaskType]map[string]interface{}
 type TaskSchedulingStatus struct {
 	Last time.Time
 	Next time.Time
+
+	// Paused is set when the periodic scheduling of the task has been suspended
+	Paused bool
+}
+
+// TaskExecution holds the outcome of a task execution awaited by a client.
+type TaskExecution struct {
+	ID       string
+	TaskType TaskType
+	Outcome  string
+	Error    string
 }
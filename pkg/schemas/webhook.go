@@ -0,0 +1,16 @@
+package schemas
+
+import (
+	"net/http"
+	"time"
+)
+
+// WebhookDelivery is a webhook request to be relayed.
+type WebhookDelivery struct {
+	Source     string
+	Event      string
+	Repository string
+	Header     http.Header
+	Body       []byte
+	ReceivedAt time.Time
+}
@@ -3,6 +3,7 @@ package store
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/xnok/mend-renovate-ce-ee-exporter/pkg/schemas"
 )
@@ -14,6 +15,18 @@ type Local struct {
 	tasks              schemas.Tasks
 	tasksMutex         sync.RWMutex
 	executedTasksCount uint64
+
+	taskScheduling      map[schemas.TaskType]schemas.TaskSchedulingStatus
+	taskSchedulingMutex sync.RWMutex
+
+	taskExecutions      map[string]localTaskExecution
+	taskExecutionsMutex sync.Mutex
+
+	webhookDeliveries      []schemas.WebhookDelivery
+	webhookDeliveriesMutex sync.RWMutex
+
+	sourceStatuses      map[string][]byte
+	sourceStatusesMutex sync.RWMutex
 }
 
 // Metrics ..
@@ -35,6 +48,22 @@ func (l *Local) SetMetric(_ context.Context, m schemas.Metric) error {
 	l.metricsMutex.Lock()
 	defer l.metricsMutex.Unlock()
 
+	m.UpdatedAt = time.Now()
+	l.metrics[m.Key()] = m
+
+	return nil
+}
+
+// IncMetric ..
+func (l *Local) IncMetric(_ context.Context, m schemas.Metric) error {
+	l.metricsMutex.Lock()
+	defer l.metricsMutex.Unlock()
+
+	if current, ok := l.metrics[m.Key()]; ok {
+		m.Value += current.Value
+	}
+
+	m.UpdatedAt = time.Now()
 	l.metrics[m.Key()] = m
 
 	return nil
@@ -93,6 +122,25 @@ func (l *Local) CurrentlyQueuedTasksCount(_ context.Context) (count uint64, err
 	return
 }
 
+// TaskLocks ..
+func (l *Local) TaskLocks(_ context.Context) (locks []schemas.TaskLock, err error) {
+	l.tasksMutex.RLock()
+	defer l.tasksMutex.RUnlock()
+
+	for tt, tasks := range l.tasks {
+		for uniqueID, processUUID := range tasks {
+			owner, _ := processUUID.(string)
+			locks = append(locks, schemas.TaskLock{
+				TaskType: tt,
+				UniqueID: uniqueID,
+				Owner:    owner,
+			})
+		}
+	}
+
+	return
+}
+
 // isTaskAlreadyQueued assess if a task is already queued or not.
 func (l *Local) isTaskAlreadyQueued(tt schemas.TaskType, uniqueID string) bool {
 	l.tasksMutex.Lock()
@@ -118,12 +166,12 @@ func (l *Local) isTaskAlreadyQueued(tt schemas.TaskType, uniqueID string) bool {
 
 // QueueTask registers that we are queueing the task.
 // It returns true if it managed to schedule it, false if it was already scheduled.
-func (l *Local) QueueTask(_ context.Context, tt schemas.TaskType, uniqueID, _ string) (bool, error) {
+func (l *Local) QueueTask(_ context.Context, tt schemas.TaskType, uniqueID, processUUID string) (bool, error) {
 	if !l.isTaskAlreadyQueued(tt, uniqueID) {
 		l.tasksMutex.Lock()
 		defer l.tasksMutex.Unlock()
 
-		l.tasks[tt][uniqueID] = nil
+		l.tasks[tt][uniqueID] = processUUID
 
 		return true, nil
 	}
@@ -144,6 +192,16 @@ func (l *Local) UnqueueTask(_ context.Context, tt schemas.TaskType, uniqueID str
 	return nil
 }
 
+// DelTaskLock ..
+func (l *Local) DelTaskLock(_ context.Context, tt schemas.TaskType, uniqueID string) error {
+	l.tasksMutex.Lock()
+	defer l.tasksMutex.Unlock()
+
+	delete(l.tasks[tt], uniqueID)
+
+	return nil
+}
+
 // ExecutedTasksCount ..
 func (l *Local) ExecutedTasksCount(_ context.Context) (uint64, error) {
 	l.tasksMutex.RLock()
@@ -151,3 +209,159 @@ func (l *Local) ExecutedTasksCount(_ context.Context) (uint64, error) {
 
 	return l.executedTasksCount, nil
 }
+
+// SetTaskSchedulingLast ..
+func (l *Local) SetTaskSchedulingLast(_ context.Context, tt schemas.TaskType, t time.Time) error {
+	l.updateTaskSchedulingStatus(tt, func(status *schemas.TaskSchedulingStatus) {
+		status.Last = t
+	})
+
+	return nil
+}
+
+// SetTaskSchedulingNext ..
+func (l *Local) SetTaskSchedulingNext(_ context.Context, tt schemas.TaskType, t time.Time) error {
+	l.updateTaskSchedulingStatus(tt, func(status *schemas.TaskSchedulingStatus) {
+		status.Next = t
+	})
+
+	return nil
+}
+
+// SetTaskSchedulingPaused ..
+func (l *Local) SetTaskSchedulingPaused(_ context.Context, tt schemas.TaskType, paused bool) error {
+	l.updateTaskSchedulingStatus(tt, func(status *schemas.TaskSchedulingStatus) {
+		status.Paused = paused
+	})
+
+	return nil
+}
+
+// updateTaskSchedulingStatus applies the update to the scheduling status of the task.
+func (l *Local) updateTaskSchedulingStatus(tt schemas.TaskType, update func(*schemas.TaskSchedulingStatus)) {
+	l.taskSchedulingMutex.Lock()
+	defer l.taskSchedulingMutex.Unlock()
+
+	if l.taskScheduling == nil {
+		l.taskScheduling = make(map[schemas.TaskType]schemas.TaskSchedulingStatus)
+	}
+
+	status := l.taskScheduling[tt]
+	update(&status)
+	l.taskScheduling[tt] = status
+}
+
+// TaskSchedulingStatus ..
+func (l *Local) TaskSchedulingStatus(_ context.Context, tt schemas.TaskType) (schemas.TaskSchedulingStatus, error) {
+	l.taskSchedulingMutex.RLock()
+	defer l.taskSchedulingMutex.RUnlock()
+
+	return l.taskScheduling[tt], nil
+}
+
+// localTaskExecution is a task execution outcome along with its expiry.
+type localTaskExecution struct {
+	schemas.TaskExecution
+	expiresAt time.Time
+}
+
+// SetTaskExecution ..
+func (l *Local) SetTaskExecution(_ context.Context, e schemas.TaskExecution, ttl time.Duration) error {
+	l.taskExecutionsMutex.Lock()
+	defer l.taskExecutionsMutex.Unlock()
+
+	if l.taskExecutions == nil {
+		l.taskExecutions = make(map[string]localTaskExecution)
+	}
+
+	now := time.Now()
+	for id, le := range l.taskExecutions {
+		if now.After(le.expiresAt) {
+			delete(l.taskExecutions, id)
+		}
+	}
+
+	l.taskExecutions[e.ID] = localTaskExecution{TaskExecution: e, expiresAt: now.Add(ttl)}
+
+	return nil
+}
+
+// TaskExecution ..
+func (l *Local) TaskExecution(_ context.Context, id string) (schemas.TaskExecution, bool, error) {
+	l.taskExecutionsMutex.Lock()
+	defer l.taskExecutionsMutex.Unlock()
+
+	le, ok := l.taskExecutions[id]
+	if !ok || time.Now().After(le.expiresAt) {
+		return schemas.TaskExecution{}, false, nil
+	}
+
+	return le.TaskExecution, true, nil
+}
+
+// BufferWebhookDelivery ..
+func (l *Local) BufferWebhookDelivery(_ context.Context, d schemas.WebhookDelivery, maxSize int64) error {
+	l.webhookDeliveriesMutex.Lock()
+	defer l.webhookDeliveriesMutex.Unlock()
+
+	l.webhookDeliveries = append(l.webhookDeliveries, d)
+	if overflow := int64(len(l.webhookDeliveries)) - maxSize; overflow > 0 {
+		l.webhookDeliveries = l.webhookDeliveries[overflow:]
+	}
+
+	return nil
+}
+
+// PopWebhookDelivery returns the oldest buffered delivery, if any.
+func (l *Local) PopWebhookDelivery(_ context.Context) (d schemas.WebhookDelivery, ok bool, err error) {
+	l.webhookDeliveriesMutex.Lock()
+	defer l.webhookDeliveriesMutex.Unlock()
+
+	if len(l.webhookDeliveries) == 0 {
+		return
+	}
+
+	d, l.webhookDeliveries = l.webhookDeliveries[0], l.webhookDeliveries[1:]
+
+	return d, true, nil
+}
+
+// RequeueWebhookDelivery puts back a delivery at the front of the buffer.
+func (l *Local) RequeueWebhookDelivery(_ context.Context, d schemas.WebhookDelivery) error {
+	l.webhookDeliveriesMutex.Lock()
+	defer l.webhookDeliveriesMutex.Unlock()
+
+	l.webhookDeliveries = append([]schemas.WebhookDelivery{d}, l.webhookDeliveries...)
+
+	return nil
+}
+
+// BufferedWebhookDeliveriesCount ..
+func (l *Local) BufferedWebhookDeliveriesCount(_ context.Context) (int64, error) {
+	l.webhookDeliveriesMutex.RLock()
+	defer l.webhookDeliveriesMutex.RUnlock()
+
+	return int64(len(l.webhookDeliveries)), nil
+}
+
+// SetSourceStatus ..
+func (l *Local) SetSourceStatus(_ context.Context, source string, status []byte) error {
+	l.sourceStatusesMutex.Lock()
+	defer l.sourceStatusesMutex.Unlock()
+
+	if l.sourceStatuses == nil {
+		l.sourceStatuses = make(map[string][]byte)
+	}
+
+	l.sourceStatuses[source] = status
+
+	return nil
+}
+
+// SourceStatus ..
+func (l *Local) SourceStatus(_ context.Context, source string) ([]byte, error) {
+	l.sourceStatusesMutex.RLock()
+	defer l.sourceStatusesMutex.RUnlock()
+
+	return l.sourceStatuses[source], nil
+}
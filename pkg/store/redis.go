@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -17,6 +18,64 @@ const (
 	redisTaskKey               string = `task`
 	redisTasksExecutedCountKey string = `tasksExecutedCount`
 	redisKeepaliveKey          string = `keepalive`
+	redisTaskSchedulingKey     string = `taskScheduling`
+	redisTaskExecutionKey      string = `taskExecution`
+	redisWebhookDeliveriesKey  string = `webhookDeliveries`
+	redisSourceStatusesKey     string = `sourceStatuses`
+	redisReplicaKey            string = `replica`
+	redisLeaderKey             string = `leader`
+
+	// redisIncMetricMaxRetries is the number of times an increment is
+	// attempted when the metrics get concurrently updated
+	redisIncMetricMaxRetries = 10
+)
+
+var (
+	// redisSetIfGreaterScript only updates the field if the provided value is greater
+	// than the current one, ensuring we keep the most recent scheduling amongst all processes.
+	redisSetIfGreaterScript = redis.NewScript(`
+local current = redis.call('HGET', KEYS[1], ARGV[1])
+if not current or tonumber(ARGV[2]) > tonumber(current) then
+	redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+	return 1
+end
+return 0
+`)
+
+	// redisDelIfEqualScript only deletes the key if it holds the provided value.
+	redisDelIfEqualScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+	// redisAcquireLeadershipScript extends the leadership if it is already held by the
+	// process or takes it over if no other process holds it.
+	redisAcquireLeadershipScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if current == ARGV[1] then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+	return 1
+end
+if not current then
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+	return 1
+end
+return 0
+`)
+
+	// redisSetIfSoonerScript only updates the field if the provided value is sooner than
+	// the current one or if the current one is already past, ensuring we keep the next
+	// upcoming scheduling amongst all processes.
+	redisSetIfSoonerScript = redis.NewScript(`
+local current = redis.call('HGET', KEYS[1], ARGV[1])
+if not current or tonumber(current) < tonumber(ARGV[3]) or tonumber(ARGV[2]) < tonumber(current) then
+	redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+	return 1
+end
+return 0
+`)
 )
 
 // Redis ..
@@ -48,6 +107,8 @@ func (r *Redis) Metrics(ctx context.Context) (schemas.Metrics, error) {
 
 // SetMetric ..
 func (r *Redis) SetMetric(ctx context.Context, m schemas.Metric) error {
+	m.UpdatedAt = time.Now()
+
 	marshalledMetric, err := msgpack.Marshal(m)
 	if err != nil {
 		return err
@@ -58,6 +119,52 @@ func (r *Redis) SetMetric(ctx context.Context, m schemas.Metric) error {
 	return err
 }
 
+// IncMetric ..
+func (r *Redis) IncMetric(ctx context.Context, m schemas.Metric) error {
+	k := string(m.Key())
+	m.UpdatedAt = time.Now()
+
+	inc := func(tx *redis.Tx) error {
+		current := m
+
+		marshalledMetric, err := tx.HGet(ctx, redisMetricsKey, k).Result()
+		if err != nil && err != redis.Nil {
+			return err
+		}
+
+		if err == nil {
+			if err = msgpack.Unmarshal([]byte(marshalledMetric), &current); err != nil {
+				return err
+			}
+
+			current.Value += m.Value
+			current.UpdatedAt = m.UpdatedAt
+		}
+
+		b, err := msgpack.Marshal(current)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, redisMetricsKey, k, b)
+
+			return nil
+		})
+
+		return err
+	}
+
+	for i := 0; i < redisIncMetricMaxRetries; i++ {
+		err := r.Watch(ctx, inc, redisMetricsKey)
+		if err != redis.TxFailedErr {
+			return err
+		}
+	}
+
+	return fmt.Errorf("incrementing metric '%s': too many concurrent updates", k)
+}
+
 // DelMetric ..
 func (r *Redis) DelMetric(ctx context.Context, k schemas.MetricKey) error {
 	_, err := r.HDel(ctx, redisMetricsKey, string(k)).Result()
@@ -101,6 +208,11 @@ func (r *Redis) MetricsCount(ctx context.Context) (int64, error) {
 // ExecutedTasksCount ..
 func (r *Redis) ExecutedTasksCount(ctx context.Context) (uint64, error) {
 	countString, err := r.Get(ctx, redisTasksExecutedCountKey).Result()
+	if err == redis.Nil {
+		// No task has been executed yet
+		return 0, nil
+	}
+
 	if err != nil {
 		return 0, err
 	}
@@ -122,6 +234,74 @@ func (r *Redis) KeepaliveExists(ctx context.Context, uuid string) (bool, error)
 	return exists == 1, err
 }
 
+// DelKeepalive removes the keepalive key of a particular UUID.
+func (r *Redis) DelKeepalive(ctx context.Context, uuid string) error {
+	return r.Del(ctx, fmt.Sprintf("%s:%s", redisKeepaliveKey, uuid)).Err()
+}
+
+// AcquireLeadership attempts to make the process the leader amongst the running ones,
+// or to extend its leadership. It returns whether the process is the leader.
+func (r *Redis) AcquireLeadership(ctx context.Context, uuid string, ttl time.Duration) (bool, error) {
+	return redisAcquireLeadershipScript.Run(ctx, r, []string{redisLeaderKey}, uuid, ttl.Milliseconds()).Bool()
+}
+
+// ReleaseLeadership gives up the leadership if it is held by the process.
+func (r *Redis) ReleaseLeadership(ctx context.Context, uuid string) error {
+	return redisDelIfEqualScript.Run(ctx, r, []string{redisLeaderKey}, uuid).Err()
+}
+
+// SetReplica publishes the description of the running process, it expires if not refreshed.
+func (r *Redis) SetReplica(ctx context.Context, replica schemas.Replica, ttl time.Duration) error {
+	marshalledReplica, err := msgpack.Marshal(replica)
+	if err != nil {
+		return err
+	}
+
+	return r.Set(ctx, fmt.Sprintf("%s:%s", redisReplicaKey, replica.UUID), marshalledReplica, ttl).Err()
+}
+
+// DelReplica removes the description of a particular process.
+func (r *Redis) DelReplica(ctx context.Context, uuid string) error {
+	return r.Del(ctx, fmt.Sprintf("%s:%s", redisReplicaKey, uuid)).Err()
+}
+
+// Replicas returns the description of the processes currently running.
+func (r *Redis) Replicas(ctx context.Context) (replicas []schemas.Replica, err error) {
+	var keys []string
+
+	iter := r.Scan(ctx, 0, fmt.Sprintf("%s:*", redisReplicaKey), 0).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+
+	if err = iter.Err(); err != nil || len(keys) == 0 {
+		return
+	}
+
+	var marshalledReplicas []interface{}
+
+	if marshalledReplicas, err = r.MGet(ctx, keys...).Result(); err != nil {
+		return
+	}
+
+	for _, marshalledReplica := range marshalledReplicas {
+		// The replica may have expired in the meantime
+		s, ok := marshalledReplica.(string)
+		if !ok {
+			continue
+		}
+
+		replica := schemas.Replica{}
+		if err = msgpack.Unmarshal([]byte(s), &replica); err != nil {
+			return
+		}
+
+		replicas = append(replicas, replica)
+	}
+
+	return
+}
+
 func getRedisQueueKey(tt schemas.TaskType, taskUUID string) string {
 	return fmt.Sprintf("%s:%v:%s", redisTaskKey, tt, taskUUID)
 }
@@ -182,6 +362,67 @@ func (r *Redis) UnqueueTask(ctx context.Context, tt schemas.TaskType, taskUUID s
 	return
 }
 
+// DelTaskLock ..
+func (r *Redis) DelTaskLock(ctx context.Context, tt schemas.TaskType, taskUUID string) error {
+	return r.Del(ctx, getRedisQueueKey(tt, taskUUID)).Err()
+}
+
+// ReleaseTasks removes the task locks held by a particular process UUID.
+// It returns the number of released tasks.
+func (r *Redis) ReleaseTasks(ctx context.Context, processUUID string) (count uint64, err error) {
+	iter := r.Scan(ctx, 0, fmt.Sprintf("%s:*", redisTaskKey), 0).Iterator()
+	for iter.Next(ctx) {
+		var deleted int64
+
+		if deleted, err = redisDelIfEqualScript.Run(ctx, r, []string{iter.Val()}, processUUID).Int64(); err != nil {
+			return
+		}
+
+		count += uint64(deleted)
+	}
+
+	err = iter.Err()
+
+	return
+}
+
+// TaskLocks ..
+func (r *Redis) TaskLocks(ctx context.Context) (locks []schemas.TaskLock, err error) {
+	iter := r.Scan(ctx, 0, fmt.Sprintf("%s:*", redisTaskKey), 0).Iterator()
+	for iter.Next(ctx) {
+		var owner string
+
+		if owner, err = r.Get(ctx, iter.Val()).Result(); err != nil {
+			// The task may have been unqueued in the meantime
+			if err == redis.Nil {
+				err = nil
+
+				continue
+			}
+
+			return
+		}
+
+		// Keys are formatted as task:<task_type>:<unique_id>
+		parts := strings.SplitN(iter.Val(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+
+		locks = append(locks, schemas.TaskLock{
+			TaskType: schemas.TaskType(parts[1]),
+			UniqueID: parts[2],
+			Owner:    owner,
+		})
+	}
+
+	if err == nil {
+		err = iter.Err()
+	}
+
+	return
+}
+
 // CurrentlyQueuedTasksCount ..
 func (r *Redis) CurrentlyQueuedTasksCount(ctx context.Context) (count uint64, err error) {
 	iter := r.Scan(ctx, 0, fmt.Sprintf("%s:*", redisTaskKey), 0).Iterator()
@@ -193,3 +434,167 @@ func (r *Redis) CurrentlyQueuedTasksCount(ctx context.Context) (count uint64, er
 
 	return
 }
+
+func getRedisTaskSchedulingField(tt schemas.TaskType, field string) string {
+	return fmt.Sprintf("%v:%s", tt, field)
+}
+
+// SetTaskSchedulingLast ..
+func (r *Redis) SetTaskSchedulingLast(ctx context.Context, tt schemas.TaskType, t time.Time) error {
+	return redisSetIfGreaterScript.Run(
+		ctx,
+		r,
+		[]string{redisTaskSchedulingKey},
+		getRedisTaskSchedulingField(tt, "last"),
+		t.UnixNano(),
+	).Err()
+}
+
+// SetTaskSchedulingNext ..
+func (r *Redis) SetTaskSchedulingNext(ctx context.Context, tt schemas.TaskType, t time.Time) error {
+	return redisSetIfSoonerScript.Run(
+		ctx,
+		r,
+		[]string{redisTaskSchedulingKey},
+		getRedisTaskSchedulingField(tt, "next"),
+		t.UnixNano(),
+		time.Now().UnixNano(),
+	).Err()
+}
+
+// SetTaskSchedulingPaused ..
+func (r *Redis) SetTaskSchedulingPaused(ctx context.Context, tt schemas.TaskType, paused bool) error {
+	if paused {
+		return r.HSet(ctx, redisTaskSchedulingKey, getRedisTaskSchedulingField(tt, "paused"), "1").Err()
+	}
+
+	return r.HDel(ctx, redisTaskSchedulingKey, getRedisTaskSchedulingField(tt, "paused")).Err()
+}
+
+// TaskSchedulingStatus ..
+func (r *Redis) TaskSchedulingStatus(ctx context.Context, tt schemas.TaskType) (status schemas.TaskSchedulingStatus, err error) {
+	var values []interface{}
+
+	values, err = r.HMGet(
+		ctx,
+		redisTaskSchedulingKey,
+		getRedisTaskSchedulingField(tt, "last"),
+		getRedisTaskSchedulingField(tt, "next"),
+		getRedisTaskSchedulingField(tt, "paused"),
+	).Result()
+	if err != nil {
+		return
+	}
+
+	status.Paused = values[2] != nil
+
+	for i, t := range []*time.Time{&status.Last, &status.Next} {
+		v, ok := values[i].(string)
+		if !ok {
+			continue
+		}
+
+		var ns int64
+
+		if ns, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return
+		}
+
+		*t = time.Unix(0, ns)
+	}
+
+	return
+}
+
+// BufferWebhookDelivery ..
+func (r *Redis) BufferWebhookDelivery(ctx context.Context, d schemas.WebhookDelivery, maxSize int64) error {
+	marshalledDelivery, err := msgpack.Marshal(d)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.RPush(ctx, redisWebhookDeliveriesKey, marshalledDelivery)
+		pipe.LTrim(ctx, redisWebhookDeliveriesKey, -maxSize, -1)
+
+		return nil
+	})
+
+	return err
+}
+
+// PopWebhookDelivery returns the oldest buffered delivery, if any.
+func (r *Redis) PopWebhookDelivery(ctx context.Context) (d schemas.WebhookDelivery, ok bool, err error) {
+	marshalledDelivery, err := r.LPop(ctx, redisWebhookDeliveriesKey).Result()
+	if err == redis.Nil {
+		return d, false, nil
+	}
+
+	if err != nil {
+		return
+	}
+
+	if err = msgpack.Unmarshal([]byte(marshalledDelivery), &d); err != nil {
+		return
+	}
+
+	return d, true, nil
+}
+
+// RequeueWebhookDelivery puts back a delivery at the front of the buffer.
+func (r *Redis) RequeueWebhookDelivery(ctx context.Context, d schemas.WebhookDelivery) error {
+	marshalledDelivery, err := msgpack.Marshal(d)
+	if err != nil {
+		return err
+	}
+
+	return r.LPush(ctx, redisWebhookDeliveriesKey, marshalledDelivery).Err()
+}
+
+// BufferedWebhookDeliveriesCount ..
+func (r *Redis) BufferedWebhookDeliveriesCount(ctx context.Context) (int64, error) {
+	return r.LLen(ctx, redisWebhookDeliveriesKey).Result()
+}
+
+// SetTaskExecution ..
+func (r *Redis) SetTaskExecution(ctx context.Context, e schemas.TaskExecution, ttl time.Duration) error {
+	marshalledExecution, err := msgpack.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	return r.Set(ctx, fmt.Sprintf("%s:%s", redisTaskExecutionKey, e.ID), marshalledExecution, ttl).Err()
+}
+
+// TaskExecution ..
+func (r *Redis) TaskExecution(ctx context.Context, id string) (e schemas.TaskExecution, ok bool, err error) {
+	b, err := r.Get(ctx, fmt.Sprintf("%s:%s", redisTaskExecutionKey, id)).Bytes()
+	if err == redis.Nil {
+		return e, false, nil
+	}
+
+	if err != nil {
+		return
+	}
+
+	if err = msgpack.Unmarshal(b, &e); err != nil {
+		return
+	}
+
+	return e, true, nil
+}
+
+// SetSourceStatus ..
+func (r *Redis) SetSourceStatus(ctx context.Context, source string, status []byte) error {
+	return r.HSet(ctx, redisSourceStatusesKey, source, status).Err()
+}
+
+// SourceStatus ..
+func (r *Redis) SourceStatus(ctx context.Context, source string) ([]byte, error) {
+	status, err := r.HGet(ctx, redisSourceStatusesKey, source).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+
+	return status, err
+}
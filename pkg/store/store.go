@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 	"go.opentelemetry.io/otel"
@@ -14,6 +15,8 @@ type Store interface {
 	// Metrics ..
 	Metrics(context.Context) (schemas.Metrics, error)
 	SetMetric(context.Context, schemas.Metric) error
+	// IncMetric adds the value of the provided metric to the stored one, atomically
+	IncMetric(context.Context, schemas.Metric) error
 	DelMetric(context.Context, schemas.MetricKey) error
 	GetMetric(context.Context, *schemas.Metric) error
 	MetricExists(context.Context, schemas.MetricKey) (bool, error)
@@ -22,14 +25,39 @@ type Store interface {
 	// twice at the risk of ending up with loads of dangling goroutines being locked
 	QueueTask(context.Context, schemas.TaskType, string, string) (bool, error)
 	UnqueueTask(context.Context, schemas.TaskType, string) error
+	// DelTaskLock removes the lock of a task without accounting it as executed
+	DelTaskLock(context.Context, schemas.TaskType, string) error
 	CurrentlyQueuedTasksCount(context.Context) (uint64, error)
+	TaskLocks(context.Context) ([]schemas.TaskLock, error)
 	ExecutedTasksCount(context.Context) (uint64, error)
+	// SetTaskSchedulingLast Helpers to keep track of when the tasks have been
+	// and will be scheduled, across all the running processes
+	SetTaskSchedulingLast(context.Context, schemas.TaskType, time.Time) error
+	SetTaskSchedulingNext(context.Context, schemas.TaskType, time.Time) error
+	SetTaskSchedulingPaused(context.Context, schemas.TaskType, bool) error
+	TaskSchedulingStatus(context.Context, schemas.TaskType) (schemas.TaskSchedulingStatus, error)
+	// SetTaskExecution Helpers to share the outcome of the task executions awaited
+	// by a client across all the running processes, they expire after the provided ttl
+	SetTaskExecution(context.Context, schemas.TaskExecution, time.Duration) error
+	TaskExecution(context.Context, string) (schemas.TaskExecution, bool, error)
+	// BufferWebhookDelivery Helpers to keep the webhook deliveries which could not
+	// be relayed, the oldest ones get dropped once the buffer size is reached
+	BufferWebhookDelivery(context.Context, schemas.WebhookDelivery, int64) error
+	PopWebhookDelivery(context.Context) (schemas.WebhookDelivery, bool, error)
+	RequeueWebhookDelivery(context.Context, schemas.WebhookDelivery) error
+	BufferedWebhookDeliveriesCount(context.Context) (int64, error)
+	// SetSourceStatus Helpers to share the raw data last fetched by the sources
+	// across all the running processes, nil is returned if there is none
+	SetSourceStatus(context.Context, string, []byte) error
+	SourceStatus(context.Context, string) ([]byte, error)
 }
 
 // NewLocalStore ..
 func NewLocalStore() Store {
 	return &Local{
-		metrics: make(schemas.Metrics),
+		metrics:        make(schemas.Metrics),
+		taskScheduling: make(map[schemas.TaskType]schemas.TaskSchedulingStatus),
+		sourceStatuses: make(map[string][]byte),
 	}
 }
 